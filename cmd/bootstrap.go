@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap [branch-name]",
+	Short: "Set up the dependencies of a worktree",
+	Long: `Set up the dependencies of a worktree, by default the one containing the
+working directory, for every ecosystem whose lockfile is at its root:
+
+  pnpm    pnpm-lock.yaml     node_modules
+  yarn    yarn.lock          node_modules
+  npm     package-lock.json  node_modules
+  go      go.sum             vendor
+  poetry  poetry.lock        .venv
+  pip     requirements.txt   .venv
+  cargo   Cargo.lock         target
+
+If the main worktree or another worktree has an identical lockfile and the
+dependency directory, the directory is hardlinked from it (or copied, with
+bootstrap.mode = "copy"); only otherwise the install command is run.
+Cargo's target is always copied. Hardlinked files are shared, so editing one
+in place changes it in every worktree that reused it. Directories the
+worktree already has are left alone.
+
+Set bootstrap.enabled to bootstrap every new worktree, and override install
+commands in [bootstrap.install].`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx, stop := interruptible(cmd.Context())
+		defer stop()
+
+		var wt *worktree.Worktree
+		if len(args) > 0 {
+			wt, err = manager.FindWorktree(ctx, args[0])
+		} else {
+			wt, err = manager.CurrentWorktree(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		results, err := manager.Bootstrap(ctx, wt.Path)
+		for _, r := range results {
+			switch r.Action {
+			case worktree.BootstrapReused:
+				fmt.Printf("🔗 %s: reused from %s\n", r.Ecosystem, r.Source)
+			case worktree.BootstrapInstalled:
+				fmt.Printf("📦 %s: installed\n", r.Ecosystem)
+			case worktree.BootstrapPresent:
+				fmt.Printf("✅ %s: already present\n", r.Ecosystem)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to bootstrap worktree: %w", err)
+		}
+		if len(results) == 0 {
+			fmt.Println("No lockfiles found")
+		}
+		return nil
+	},
+}
+
+// applyBootstrapFlag overrides the bootstrap.enabled setting of manager with
+// the --bootstrap flag of cmd, if it was given.
+func applyBootstrapFlag(cmd *cobra.Command, manager *worktree.Manager) {
+	if enabled, err := cmd.Flags().GetBool("bootstrap"); err == nil && cmd.Flags().Changed("bootstrap") {
+		manager.Config().Bootstrap.Enabled = enabled
+	}
+}
@@ -1,27 +1,35 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cleanDryRun bool
-	cleanForce  bool
+	cleanDryRun    bool
+	cleanForce     forceFlag
+	cleanNoArchive bool
 )
 
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Remove worktrees for merged branches",
 	Long: `Batch remove worktrees for branches that have been merged into the main branch.
-This excludes main/master/develop branches by default.`,
+Branches merged with GitHub's squash or rebase buttons are detected by comparing
+patches against the main branch. When a forge token such as GITHUB_TOKEN or
+GITLAB_TOKEN is set, branches whose pull request was merged are detected as well.
+This excludes main/master/develop branches by default.
+
+Worktrees with uncommitted changes, untracked files, unpushed commits, a lock
+or processes using them are skipped unless the risk is accepted with
+--force=<risk>,... (see 'giwo remove --help'). Locked worktrees are left out
+before asking for confirmation; lock a worktree with 'giwo lock' to keep it.
+Removed worktrees are archived and can be brought back with
+'giwo restore <branch>'.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
@@ -48,9 +56,19 @@ This excludes main/master/develop branches by default.`,
 		}
 
 		var toRemove []string
-		for _, branch := range mergedBranches {
-			if _, exists := worktreeMap[branch]; exists {
-				toRemove = append(toRemove, branch)
+		reasons := make(map[string]string)
+		for _, merged := range mergedBranches {
+			if wt, exists := worktreeMap[merged.Branch]; exists {
+				if wt.Locked && !cleanForce.accepts(worktree.RiskLocked) {
+					skipped := fmt.Sprintf("🔒 Skipping locked worktree '%s'", merged.Branch)
+					if wt.LockReason != "" {
+						skipped += fmt.Sprintf(" (%s)", wt.LockReason)
+					}
+					fmt.Println(skipped)
+					continue
+				}
+				toRemove = append(toRemove, merged.Branch)
+				reasons[merged.Branch] = merged.Description()
 			}
 		}
 
@@ -63,10 +81,17 @@ This excludes main/master/develop branches by default.`,
 		for _, branch := range toRemove {
 			wt := worktreeMap[branch]
 			status := "clean"
-			if !wt.IsClean {
+			if wt.Prunable {
+				status = "👻 " + prunableStatus(wt)
+			} else if wt.Error != "" {
+				status = "❌ status unknown"
+			} else if !wt.IsClean {
 				status = "⚠️  dirty"
 			}
-			fmt.Printf("  - %s (%s)\n", branch, status)
+			if wt.Locked {
+				status += ", " + lockStatus(wt)
+			}
+			fmt.Printf("  - %s (%s, %s)\n", branch, status, reasons[branch])
 		}
 
 		if cleanDryRun {
@@ -74,21 +99,20 @@ This excludes main/master/develop branches by default.`,
 			return nil
 		}
 
-		if !cleanForce {
-			fmt.Printf("\nRemove %d worktree(s)? [y/N]: ", len(toRemove))
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			if strings.ToLower(strings.TrimSpace(response)) != "y" {
-				fmt.Println("Operation cancelled")
-				return nil
-			}
+		if !cleanForce.set && !confirm(fmt.Sprintf("\nRemove %d worktree(s)?", len(toRemove))) {
+			fmt.Println("Operation cancelled")
+			return nil
 		}
 
 		removed := 0
 		for _, branch := range toRemove {
 			fmt.Printf("🗑️  Removing worktree '%s'...\n", branch)
-			if err := manager.Remove(ctx, branch, true, false); err != nil {
+			opts := worktree.RemoveOptions{Yes: true, Accept: cleanForce.accept, NoArchive: cleanNoArchive}
+			if err := manager.Remove(ctx, branch, opts); err != nil {
 				fmt.Printf("⚠️  Failed to remove '%s': %v\n", branch, err)
+				if hint := riskHint(err); hint != "" {
+					fmt.Println(hint)
+				}
 				continue
 			}
 			removed++
@@ -101,5 +125,7 @@ This excludes main/master/develop branches by default.`,
 
 func init() {
 	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Show what would be removed without actually removing")
-	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "Force removal without confirmation")
+	addForceFlag(cleanCmd.Flags(), &cleanForce, forceUsage)
+	cleanCmd.Flags().BoolVar(&cleanNoArchive, "no-archive", false, "Do not archive removed worktrees, so that they cannot be restored")
 }
+
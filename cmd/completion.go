@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Completion runs on every keypress, so forge lookups are cached and bounded.
+const (
+	pullRequestCacheTTL      = 5 * time.Minute
+	pullRequestCompleteLimit = 3 * time.Second
+)
+
+// completeWorktreeBranches completes the first argument with the branches of
+// existing worktrees, excluding the main worktree.
+func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := newManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	worktrees, err := manager.Worktrees(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []cobra.Completion
+	for _, wt := range worktrees {
+		if wt.Path == manager.RepoRoot() || wt.Branch == "" {
+			continue
+		}
+		if strings.HasPrefix(wt.Branch, toComplete) {
+			completions = append(completions, wt.Branch)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBranches completes local and remote-tracking branch names.
+func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	manager, err := newManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	branches, err := manager.Branches(cmd.Context(), true)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []cobra.Completion
+	for _, branch := range branches {
+		if strings.HasPrefix(branch, toComplete) {
+			completions = append(completions, branch)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePullRequests completes open pull request numbers, described by
+// their titles, when the forge of the default remote is known.
+func completePullRequests(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	manager, err := newManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), pullRequestCompleteLimit)
+	defer cancel()
+
+	prs, err := manager.OpenPullRequests(ctx, pullRequestCacheTTL)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, pr := range prs {
+		number := strconv.Itoa(pr.Number)
+		if strings.HasPrefix(number, strings.TrimPrefix(toComplete, "#")) {
+			completions = append(completions, cobra.CompletionWithDesc(number, fmt.Sprintf("%s (%s)", pr.Title, pr.HeadRef)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeFirstPullRequest completes the first argument with pull request numbers.
+func completeFirstPullRequest(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completePullRequests(cmd, args, toComplete)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+var configFormat string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show the effective configuration",
+	Long: `Display the configuration giwo uses for the current repository.
+Settings are merged from the user config (~/.config/giwo/config.toml),
+the repository config (.giwo.toml or .giwo.yaml) and GIWO_* environment variables.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		cfg := manager.Config()
+		switch configFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(cfg)
+		case "toml":
+			for _, file := range cfg.Files {
+				fmt.Printf("# loaded from %s\n", file)
+			}
+			return toml.NewEncoder(os.Stdout).Encode(cfg)
+		default:
+			return fmt.Errorf("unsupported format: %s", configFormat)
+		}
+	},
+}
+
+func init() {
+	configCmd.Flags().StringVar(&configFormat, "format", "toml", "Output format (toml, json)")
+}
@@ -1,46 +1,106 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
 	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/config"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
-	createForce bool
-	createBase  string
+	createForce  bool
+	createBase   string
+	createPR     int
+	createCD     bool
+	createNew    bool
+	createFrom   string
+	createDetach bool
+	createFetch  string
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create <branch-name>",
 	Short: "Create a new worktree",
-	Long: `Create a new worktree based on the current branch.
-The worktree will be placed in .worktree/<branch-name> directory and
-automatically create and switch to the new branch.
+	Long: `Create a worktree for a branch. It is placed in .worktree/<branch-name>
+unless the worktree_path setting gives another layout, such as
+'../{repo}-{slug}'.
 
-By default, the new worktree will be created from the current branch.
-Use --base to specify a different base branch.`,
-	Args: cobra.ExactArgs(1),
+An existing local branch is checked out, and an existing branch on the
+default remote is checked out into a new local branch that tracks it.
+If the branch is already checked out in another worktree, giwo offers to
+switch to that worktree instead.
+
+Otherwise a new branch is created from the current branch, or from
+default_base when it is set in the giwo configuration. Use --base to
+specify a different base branch, and --new to always create a new branch.
+Use --from to start the new branch at any revision instead, such as a tag,
+a commit SHA or HEAD~3.
+
+Use --detach to check out a revision without a branch, for example to
+inspect a release: 'giwo create v1.2.0 --detach' checks out the tag v1.2.0
+into .worktree/v1.2.0. Combine it with --from to name the worktree freely.
+
+Use --pr <number> instead of a branch name to check out a pull request
+into .worktree/pr-<number>.
+
+Before creating the worktree giwo fetches from the default remote according
+to the fetch policy, set with --fetch or the fetch setting:
+
+  always     fetch all branches (default)
+  base-only  fetch only the base branch
+  if-stale   fetch all branches unless the last fetch is newer than fetch_max_age
+  never      use the remote-tracking branches as they are
+
+If the remote is unreachable, giwo continues offline and starts the new
+branch from the local base branch.
+
+With --bootstrap, or the bootstrap.enabled setting, dependencies such as
+node_modules are reused from a worktree with an identical lockfile or
+installed, see 'giwo bootstrap --help'.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if createPR > 0 {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runCreateCommand,
 }
 
 func runCreateCommand(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+
+	if createPR > 0 {
+		return createPullRequestWorktree(cmd, createPR, createForce, createCD)
+	}
+
 	branchName := args[0]
 
 	if err := utils.ValidateBranchName(branchName); err != nil {
 		return fmt.Errorf("invalid branch name: %w", err)
 	}
 
-	manager, err := worktree.New()
+	fetch := config.FetchPolicy(createFetch)
+	if fetch != "" && !slices.Contains(config.FetchPolicies, fetch) {
+		return fmt.Errorf("invalid --fetch %q: must be one of %s", createFetch, strings.Join(fetchPolicyNames(), ", "))
+	}
+
+	manager, err := newManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize manager: %w", err)
 	}
+	applyBootstrapFlag(cmd, manager)
 
 	baseBranch := createBase
 	if baseBranch == "" {
+		baseBranch = manager.Config().DefaultBase
+	}
+	if baseBranch == "" && createFrom == "" && !createDetach {
 		// Use current branch as default
 		baseBranch, err = manager.GetCurrentBranch(ctx)
 		if err != nil {
@@ -48,21 +108,106 @@ func runCreateCommand(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("🌱 Creating worktree '%s' based on '%s'...\n", branchName, baseBranch)
+	fmt.Printf("🌱 Creating worktree '%s'...\n", branchName)
 
-	if err := manager.Create(ctx, branchName, baseBranch, createForce); err != nil {
+	opts := worktree.CreateOptions{
+		Base:   baseBranch,
+		From:   createFrom,
+		Detach: createDetach,
+		Force:  createForce,
+		New:    createNew,
+		Fetch:  fetch,
+	}
+	createCtx, stop := interruptible(ctx)
+	source, err := manager.Create(createCtx, branchName, opts)
+	stop()
+	var checkedOut *giwoerrors.CheckedOutError
+	if errors.As(err, &checkedOut) {
+		fmt.Printf("⚠️  Branch '%s' is already checked out at %s\n", checkedOut.Branch, checkedOut.Path)
+		if !confirm("Switch to it?") {
+			return nil
+		}
+		return switchToWorktree(ctx, manager, &worktree.Worktree{Branch: checkedOut.Branch, Path: checkedOut.Path}, false)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to create worktree: %w", err)
 	}
 
-	worktreePath := fmt.Sprintf("%s/%s", manager.WorktreeDir(), branchName)
+	switch source {
+	case worktree.BranchSourceDetached:
+		from := createFrom
+		if from == "" {
+			from = branchName
+		}
+		fmt.Printf("📌 Checked out '%s' with a detached HEAD\n", from)
+	case worktree.BranchSourceLocal:
+		fmt.Printf("🔗 Checked out existing branch '%s'\n", branchName)
+	case worktree.BranchSourceRemote:
+		fmt.Printf("🔗 Created branch '%s' tracking '%s/%s'\n", branchName, manager.Config().DefaultRemote, branchName)
+	default:
+		from := createFrom
+		if from == "" {
+			from = baseBranch
+		}
+		fmt.Printf("🌿 Created branch '%s' from '%s'\n", branchName, from)
+	}
+
+	return announceWorktree(manager, branchName, createCD)
+}
+
+// announceWorktree reports the newly created worktree of branch and, if cd is
+// set, asks the shell wrapper to change into it.
+func announceWorktree(manager *worktree.Manager, branch string, cd bool) error {
+	worktreePath, err := manager.WorktreePath(branch)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("✅ Worktree created successfully at: %s\n", worktreePath)
-	fmt.Printf("💡 Run 'cd %s' to switch to the new worktree\n", worktreePath)
 
+	if cd {
+		ok, err := requestShellCD(worktreePath)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		fmt.Println("⚠️  --cd needs shell integration, see 'giwo shell-init --help'")
+	}
+
+	fmt.Printf("💡 Run 'cd %s' to switch to the new worktree\n", worktreePath)
 	return nil
 }
 
+// fetchPolicyNames returns the names of the fetch policies.
+func fetchPolicyNames() []string {
+	names := make([]string, len(config.FetchPolicies))
+	for i, p := range config.FetchPolicies {
+		names[i] = string(p)
+	}
+	return names
+}
 
 func init() {
 	createCmd.Flags().BoolVar(&createForce, "force", false, "Force creation even if directory exists")
 	createCmd.Flags().StringVar(&createBase, "base", "", "Base branch to create worktree from (default: current branch)")
+	createCmd.Flags().IntVar(&createPR, "pr", 0, "Create the worktree from a pull request number")
+	createCmd.Flags().BoolVar(&createCD, "cd", false, "Change into the new worktree (requires shell integration)")
+	createCmd.Flags().BoolVar(&createNew, "new", false, "Always create a new branch from the base instead of checking out an existing one")
+	createCmd.Flags().StringVar(&createFrom, "from", "", "Start the new branch at a revision such as a tag, SHA or HEAD~3 (implies --new)")
+	createCmd.Flags().BoolVar(&createDetach, "detach", false, "Check out the revision with a detached HEAD instead of on a branch")
+	createCmd.Flags().StringVar(&createFetch, "fetch", "", "Fetch policy: always, base-only, if-stale or never (default: fetch setting)")
+	createCmd.Flags().Bool("bootstrap", false, "Install dependencies or reuse them from other worktrees (default: bootstrap.enabled setting)")
+
+	_ = createCmd.RegisterFlagCompletionFunc("base", completeBranches)
+	_ = createCmd.RegisterFlagCompletionFunc("from", completeBranches)
+	_ = createCmd.RegisterFlagCompletionFunc("pr", completePullRequests)
+	_ = createCmd.RegisterFlagCompletionFunc("fetch", cobra.FixedCompletions(fetchPolicyNames(), cobra.ShellCompDirectiveNoFileComp))
+	createCmd.MarkFlagsMutuallyExclusive("pr", "base")
+	createCmd.MarkFlagsMutuallyExclusive("pr", "new")
+	createCmd.MarkFlagsMutuallyExclusive("pr", "from")
+	createCmd.MarkFlagsMutuallyExclusive("pr", "detach")
+	createCmd.MarkFlagsMutuallyExclusive("base", "from")
+	createCmd.MarkFlagsMutuallyExclusive("base", "detach")
+	createCmd.MarkFlagsMutuallyExclusive("new", "detach")
 }
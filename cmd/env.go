@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envFormat string
+	envAssign bool
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env [branch-name]",
+	Short: "Print the environment of a worktree",
+	Long: `Print the environment of a worktree as shell export statements, so that
+dev servers, docker-compose and Makefiles keep their resources apart:
+
+  eval "$(giwo env)"
+
+The environment holds GIWO_BRANCH, GIWO_WORKTREE, GIWO_REPO_ROOT, GIWO_INDEX,
+the ports reserved for the worktree as GIWO_PORT and GIWO_PORT_COUNT,
+COMPOSE_PROJECT_NAME derived from the repository and branch, and the variables
+configured in env.vars. Without a branch, the worktree containing the working
+directory is used.
+
+Printing the environment changes nothing: worktrees get their index and ports
+when they are created. Pass --assign to give them to a worktree created before
+ports were reserved.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		var wt *worktree.Worktree
+		if len(args) > 0 {
+			wt, err = findEnvWorktree(cmd, manager, args[0])
+		} else {
+			wt, err = manager.CurrentWorktree(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		name := wt.Branch
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			return fmt.Errorf("worktree at %s is detached, pass the name it was created with", wt.Path)
+		}
+
+		vars, err := manager.Env(ctx, wt, name, envAssign)
+		if err != nil {
+			return fmt.Errorf("failed to build environment: %w", err)
+		}
+		output, err := worktree.FormatEnv(vars, worktree.EnvFormat(envFormat))
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(output)
+		return nil
+	},
+}
+
+// findEnvWorktree returns the worktree of branch, including the main worktree.
+func findEnvWorktree(cmd *cobra.Command, manager *worktree.Manager, branch string) (*worktree.Worktree, error) {
+	worktrees, err := manager.Worktrees(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range worktrees {
+		if wt.IsMain && wt.Branch == branch {
+			return wt, nil
+		}
+	}
+	return manager.FindWorktree(cmd.Context(), branch)
+}
+
+func envFormatNames() []string {
+	names := make([]string, len(worktree.EnvFormats))
+	for i, f := range worktree.EnvFormats {
+		names[i] = string(f)
+	}
+	return names
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envFormat, "format", string(worktree.EnvFormatSh), "Output format (sh, fish, dotenv, json)")
+	envCmd.Flags().BoolVar(&envAssign, "assign", false, "Assign an index and ports to a worktree that has none yet")
+	_ = envCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(envFormatNames(), cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/pflag"
+)
+
+// forceFlag is the value of --force on commands that remove worktrees.
+// A bare --force skips the confirmation prompt, while --force=<risk>,...
+// also accepts the named risks found by the removal preflight.
+type forceFlag struct {
+	set    bool
+	accept []worktree.Risk
+}
+
+// String implements pflag.Value.
+func (f *forceFlag) String() string {
+	if len(f.accept) > 0 {
+		names := make([]string, len(f.accept))
+		for i, risk := range f.accept {
+			names[i] = string(risk)
+		}
+		return strings.Join(names, ",")
+	}
+	return fmt.Sprint(f.set)
+}
+
+// Set implements pflag.Value.
+func (f *forceFlag) Set(s string) error {
+	switch s {
+	case "true":
+		f.set = true
+		return nil
+	case "false":
+		f.set, f.accept = false, nil
+		return nil
+	}
+
+	risks, err := worktree.ParseRisks(s)
+	if err != nil {
+		return err
+	}
+	f.set = true
+	for _, risk := range risks {
+		if !slices.Contains(f.accept, risk) {
+			f.accept = append(f.accept, risk)
+		}
+	}
+	return nil
+}
+
+// accepts reports whether risk was accepted with --force=<risk>.
+func (f *forceFlag) accepts(risk worktree.Risk) bool {
+	return slices.Contains(f.accept, risk)
+}
+
+// Type implements pflag.Value.
+func (f *forceFlag) Type() string {
+	return "risks"
+}
+
+// addForceFlag registers f as --force on flags.
+func addForceFlag(flags *pflag.FlagSet, f *forceFlag, usage string) {
+	flag := flags.VarPF(f, "force", "", usage)
+	flag.NoOptDefVal = "true"
+}
+
+// forceUsage describes the risks --force accepts.
+var forceUsage = fmt.Sprintf("Skip confirmation; with =<risk>,... also accept those risks (%s, all)", strings.Join(riskNames(), ", "))
+
+// riskNames returns the names of all risks.
+func riskNames() []string {
+	names := make([]string, len(worktree.Risks))
+	for i, risk := range worktree.Risks {
+		names[i] = string(risk)
+	}
+	return names
+}
+
+// riskHint returns advice on overriding the risks in err, or "" if err is not
+// a refused removal.
+func riskHint(err error) string {
+	var riskErr *giwoerrors.RiskError
+	if !errors.As(err, &riskErr) {
+		return ""
+	}
+	hint := fmt.Sprintf("💡 Rerun with --force=%s to remove '%s' anyway", strings.Join(riskErr.Risks, ","), riskErr.Branch)
+	if slices.Contains(riskErr.Risks, string(worktree.RiskLocked)) {
+		hint += fmt.Sprintf(", or run 'giwo unlock %s' first", riskErr.Branch)
+	}
+	return hint
+}
@@ -21,7 +21,7 @@ var listCmd = &cobra.Command{
 	Short:   "List all worktrees",
 	Long:    `Display a list of all worktrees with their status information.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
@@ -37,6 +37,12 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
+		for _, wt := range worktrees {
+			if wt.Error != "" {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to read status of '%s': %s\n", wt.Name(), wt.Error)
+			}
+		}
+
 		format := worktree.OutputFormat(listFormat)
 		switch format {
 		case worktree.OutputFormatJSON:
@@ -54,17 +60,32 @@ func printTable(worktrees []*worktree.Worktree, verbose bool) error {
 	defer w.Flush()
 
 	if verbose {
-		fmt.Fprintf(w, "BRANCH\tPATH\tSTATUS\tAHEAD/BEHIND\tCHANGES\tLAST COMMIT\tAGE\n")
+		fmt.Fprintf(w, "BRANCH\tPATH\tSTATUS\tAHEAD/BEHIND\tCHANGES\tPORTS\tLAST COMMIT\tAGE\n")
 		for _, wt := range worktrees {
 			status := "🌱"
 			if wt.IsMain {
 				status = "🏠"
+			} else if wt.Bare {
+				status = "📦"
+			} else if wt.Prunable {
+				status = "👻"
+			} else if wt.Error != "" {
+				status = "❌"
 			} else if !wt.IsClean {
 				status = "⚠️"
 			}
+			if lock := lockStatus(wt); lock != "" {
+				status += " " + lock
+			}
 
 			changes := fmt.Sprintf("M:%d A:%d D:%d", wt.Modified, wt.Added, wt.Deleted)
-			if wt.IsClean {
+			if wt.Bare {
+				changes = "bare"
+			} else if wt.Prunable {
+				changes = prunableStatus(wt)
+			} else if wt.Error != "" {
+				changes = "unknown"
+			} else if wt.IsClean {
 				changes = "clean"
 			}
 
@@ -75,8 +96,13 @@ func printTable(worktrees []*worktree.Worktree, verbose bool) error {
 				aheadBehind = "up-to-date"
 			}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-				wt.Branch, wt.Path, status, aheadBehind, changes,
+			ports := "-"
+			if wt.Metadata != nil && !wt.Metadata.Ports.IsZero() {
+				ports = wt.Metadata.Ports.String()
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				wt.Name(), wt.Path, status, aheadBehind, changes, ports,
 				truncateString(wt.LastCommit, 50), wt.CommitAge)
 		}
 	} else {
@@ -85,19 +111,47 @@ func printTable(worktrees []*worktree.Worktree, verbose bool) error {
 			status := "🌱"
 			if wt.IsMain {
 				status = "🏠 main"
+			} else if wt.Bare {
+				status = "📦 bare"
+			} else if wt.Prunable {
+				status = "👻 " + prunableStatus(wt)
+			} else if wt.Error != "" {
+				status = "❌ error"
 			} else if !wt.IsClean {
 				status = "⚠️  dirty"
 			} else {
 				status = "✅ clean"
 			}
+			if lock := lockStatus(wt); lock != "" {
+				status += ", " + lock
+			}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\n", wt.Branch, wt.Path, status)
+			fmt.Fprintf(w, "%s\t%s\t%s\n", wt.Name(), wt.Path, status)
 		}
 	}
 
 	return nil
 }
 
+// lockStatus describes the lock of wt, or returns "" if it is not locked.
+func lockStatus(wt *worktree.Worktree) string {
+	if !wt.Locked {
+		return ""
+	}
+	if wt.LockReason == "" {
+		return "🔒 locked"
+	}
+	return "🔒 locked: " + wt.LockReason
+}
+
+// prunableStatus describes why wt can be pruned.
+func prunableStatus(wt *worktree.Worktree) string {
+	if wt.PrunableReason == "" {
+		return "prunable"
+	}
+	return "prunable: " + wt.PrunableReason
+}
+
 func printJSON(worktrees []*worktree.Worktree) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -106,7 +160,7 @@ func printJSON(worktrees []*worktree.Worktree) error {
 
 func printSimple(worktrees []*worktree.Worktree) error {
 	for _, wt := range worktrees {
-		fmt.Printf("%s\t%s\n", wt.Branch, wt.Path)
+		fmt.Printf("%s\t%s\n", wt.Name(), wt.Path)
 	}
 	return nil
 }
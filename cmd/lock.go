@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var lockReason string
+
+var lockCmd = &cobra.Command{
+	Use:   "lock <branch-name>",
+	Short: "Lock a worktree against removal",
+	Long: `Lock a worktree with 'git worktree lock'. Git will not prune or move a locked
+worktree, and 'giwo remove' and 'giwo clean' skip it unless --force=locked is
+given. Use this for worktrees on removable drives or ones you want to keep
+around after their branch was merged.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branchName := args[0]
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		if err := manager.Lock(cmd.Context(), branchName, lockReason); err != nil {
+			return fmt.Errorf("failed to lock worktree: %w", err)
+		}
+
+		fmt.Printf("🔒 Locked worktree '%s'\n", branchName)
+		return nil
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:               "unlock <branch-name>",
+	Short:             "Unlock a locked worktree",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branchName := args[0]
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		if err := manager.Unlock(cmd.Context(), branchName); err != nil {
+			return fmt.Errorf("failed to unlock worktree: %w", err)
+		}
+
+		fmt.Printf("🔓 Unlocked worktree '%s'\n", branchName)
+		return nil
+	},
+}
+
+func init() {
+	lockCmd.Flags().StringVar(&lockReason, "reason", "", "Why the worktree is locked, shown by 'giwo list'")
+}
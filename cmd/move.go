@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var moveCmd = &cobra.Command{
+	Use:     "mv <branch-name> <new-branch-name>",
+	Aliases: []string{"move", "rename"},
+	Short:   "Rename a worktree and its branch",
+	Long: `Rename the branch of a worktree and move the worktree to the path of the
+new branch name. giwo's notes follow the branch, and templates and the .envrc
+are rendered again for the new name and path. If the branch tracked the remote
+branch of the same name, its upstream is changed to the new name once that has
+been pushed; until then it keeps tracking the old one.
+
+Pass the same name twice to only move the worktree, for example to the new
+layout after changing the worktree_path setting:
+
+  giwo mv feature-auth feature-auth`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldBranch, newBranch := args[0], args[1]
+
+		if err := utils.ValidateBranchName(newBranch); err != nil {
+			return fmt.Errorf("invalid branch name: %w", err)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		wt, err := manager.FindWorktree(ctx, oldBranch)
+		if err != nil {
+			return fmt.Errorf("failed to move worktree: %w", err)
+		}
+		insideWorktree := isInsideDir(wt.Path)
+
+		fmt.Printf("🚚 Moving worktree '%s' to '%s'...\n", oldBranch, newBranch)
+
+		newPath, err := manager.Move(ctx, oldBranch, newBranch)
+		if err != nil {
+			return fmt.Errorf("failed to move worktree: %w", err)
+		}
+
+		if newPath == wt.Path && newBranch == oldBranch {
+			fmt.Printf("✅ Worktree is already at: %s\n", newPath)
+			return nil
+		}
+		fmt.Printf("✅ Worktree moved to: %s\n", newPath)
+
+		// Follow the worktree if the shell was inside it
+		if insideWorktree && newPath != wt.Path {
+			ok, err := requestShellCD(newPath)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Printf("💡 The current directory was moved. Run 'cd %s'\n", newPath)
+			}
+		}
+
+		return nil
+	},
+}
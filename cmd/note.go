@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noteTags       []string
+	noteRemoveTags []string
+	noteIssue      string
+	notePR         int
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note <branch-name> [description]",
+	Short: "Show or edit the notes of a worktree",
+	Long: `Show or edit what giwo remembers about a worktree: its description, tags
+and linked issue or pull request, alongside the base branch, creation time and
+last access recorded automatically.
+Without a description or flags, the current notes are shown.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branchName := args[0]
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		if !manager.BranchExists(ctx, branchName) {
+			return fmt.Errorf("branch '%s' does not exist", branchName)
+		}
+
+		store, err := manager.Metadata(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open metadata: %w", err)
+		}
+
+		flags := cmd.Flags()
+		edit := len(args) > 1 || flags.Changed("tag") || flags.Changed("remove-tag") ||
+			flags.Changed("issue") || flags.Changed("pr")
+		if edit {
+			err = store.Update(branchName, func(md *worktree.Metadata) {
+				if len(args) > 1 {
+					md.Description = args[1]
+				}
+				md.AddTags(noteTags...)
+				md.RemoveTags(noteRemoveTags...)
+				if flags.Changed("issue") {
+					md.Issue = noteIssue
+				}
+				if flags.Changed("pr") {
+					md.PullRequest = notePR
+				}
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update notes: %w", err)
+			}
+			fmt.Printf("📝 Updated notes for '%s'\n", branchName)
+		}
+
+		md, err := store.Get(branchName)
+		if err != nil {
+			return fmt.Errorf("failed to read notes: %w", err)
+		}
+		if md == nil {
+			fmt.Printf("No notes for '%s'\n", branchName)
+			return nil
+		}
+
+		printMetadata(md)
+		return nil
+	},
+}
+
+// printMetadata prints the non-empty fields of md.
+func printMetadata(md *worktree.Metadata) {
+	if md.Description != "" {
+		fmt.Printf("Description:   %s\n", md.Description)
+	}
+	if len(md.Tags) > 0 {
+		fmt.Printf("Tags:          %s\n", strings.Join(md.Tags, ", "))
+	}
+	if md.Issue != "" {
+		fmt.Printf("Issue:         %s\n", md.Issue)
+	}
+	if md.PullRequest != 0 {
+		fmt.Printf("Pull request:  #%d\n", md.PullRequest)
+	}
+	if md.Base != "" {
+		fmt.Printf("Base:          %s\n", md.Base)
+	}
+	if !md.Ports.IsZero() {
+		fmt.Printf("Ports:         %s\n", md.Ports)
+	}
+	if !md.CreatedAt.IsZero() {
+		fmt.Printf("Created:       %s\n", md.CreatedAt.Format(time.DateTime))
+	}
+	if !md.LastAccessedAt.IsZero() {
+		fmt.Printf("Last accessed: %s\n", md.LastAccessedAt.Format(time.DateTime))
+	}
+}
+
+func init() {
+	noteCmd.Flags().StringSliceVarP(&noteTags, "tag", "t", nil, "Add a tag (repeatable)")
+	noteCmd.Flags().StringSliceVar(&noteRemoveTags, "remove-tag", nil, "Remove a tag (repeatable)")
+	noteCmd.Flags().StringVar(&noteIssue, "issue", "", "Link an issue, such as 123 or PROJ-42 (empty to unlink)")
+	noteCmd.Flags().IntVar(&notePR, "pr", 0, "Link a pull request number (0 to unlink)")
+}
@@ -1,39 +1,47 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 
-	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Remove administrative files for orphaned worktrees",
-	Long:  `Remove administrative files for orphaned worktrees. This is a wrapper around 'git worktree prune'.`,
+	Long: `Remove administrative files for orphaned worktrees. This is a wrapper around 'git worktree prune'.
+
+It also drops the metadata giwo keeps of worktrees that no longer exist, such
+as those removed with 'git worktree remove' or deleted by hand, releasing their
+index and ports.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
 
 		fmt.Println("🧹 Pruning orphaned worktree administrative files...")
 
-		gitCmd := exec.Command("git", "worktree", "prune", "-v")
-		gitCmd.Dir = manager.RepoRoot()
-
-		output, err := gitCmd.CombinedOutput()
+		output, err := manager.Prune(context.Background())
 		if err != nil {
-			return fmt.Errorf("failed to prune worktrees: %w", err)
+			return err
 		}
 
 		if len(output) > 0 {
-			fmt.Printf("%s", output)
+			fmt.Println(output)
 		} else {
 			fmt.Println("✅ No orphaned administrative files found")
 		}
 
+		pruned, err := manager.PruneMetadata(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to prune worktree metadata: %w", err)
+		}
+		for _, branch := range pruned {
+			fmt.Printf("🗑️  Dropped metadata of removed worktree '%s'\n", branch)
+		}
+
 		return nil
 	},
 }
@@ -2,14 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
-	removeForce      bool
+	removeForce      forceFlag
 	removeKeepBranch bool
+	removeNoArchive  bool
 )
 
 var removeCmd = &cobra.Command{
@@ -17,12 +21,22 @@ var removeCmd = &cobra.Command{
 	Aliases: []string{"rm", "delete"},
 	Short:   "Remove a worktree",
 	Long: `Remove the specified worktree and optionally delete the associated local branch.
-By default, the local branch will be deleted unless --keep-branch is specified.`,
-	Args: cobra.ExactArgs(1),
+By default, the local branch will be deleted unless --keep-branch is specified.
+
+Before removing anything, giwo checks for uncommitted changes, untracked files,
+unpushed commits, an unmerged branch, a locked worktree and processes using the
+directory. Removal is refused while any of these risks is found unless it is
+accepted with --force=<risk>,... (or --force=all). A bare --force only skips
+the confirmation prompt.
+
+The worktree content and branch tip are archived before removal, so that
+'giwo restore <branch>' can bring them back until the archive expires.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		branchName := args[0]
 
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
@@ -30,21 +44,66 @@ By default, the local branch will be deleted unless --keep-branch is specified.`
 		fmt.Printf("🗑️  Removing worktree '%s'...\n", branchName)
 
 		ctx := cmd.Context()
-		if err := manager.Remove(ctx, branchName, removeForce, removeKeepBranch); err != nil {
+		wt, err := manager.FindWorktree(ctx, branchName)
+		if err != nil {
 			return fmt.Errorf("failed to remove worktree: %w", err)
 		}
+		insideWorktree := isInsideDir(wt.Path)
+
+		// Detached worktrees have no branch to delete
+		hasBranch := manager.BranchExists(ctx, branchName)
+
+		opts := worktree.RemoveOptions{
+			KeepBranch: removeKeepBranch,
+			Yes:        removeForce.set,
+			Accept:     removeForce.accept,
+			NoArchive:  removeNoArchive,
+		}
+		if err := manager.Remove(ctx, branchName, opts); err != nil {
+			if hint := riskHint(err); hint != "" {
+				fmt.Println(hint)
+			}
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+
+		// Leave the removed directory if the shell was inside it
+		if insideWorktree {
+			ok, err := requestShellCD(manager.RepoRoot())
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Printf("💡 The current directory was removed. Run 'cd %s'\n", manager.RepoRoot())
+			}
+		}
 
 		if removeKeepBranch {
 			fmt.Printf("✅ Worktree removed successfully (branch kept)\n")
+		} else if !hasBranch {
+			fmt.Printf("✅ Worktree removed successfully\n")
 		} else {
 			fmt.Printf("✅ Worktree and branch removed successfully\n")
 		}
+		if !removeNoArchive {
+			fmt.Printf("💡 Run 'giwo restore %s' to bring it back\n", branchName)
+		}
 
 		return nil
 	},
 }
 
+// isInsideDir reports whether the current directory is dir or below it.
+func isInsideDir(dir string) bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, wd)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func init() {
-	removeCmd.Flags().BoolVar(&removeForce, "force", false, "Force removal without confirmation")
+	addForceFlag(removeCmd.Flags(), &removeForce, forceUsage)
 	removeCmd.Flags().BoolVar(&removeKeepBranch, "keep-branch", false, "Keep the local branch after removing worktree")
+	removeCmd.Flags().BoolVar(&removeNoArchive, "no-archive", false, "Do not archive the worktree, so that it cannot be restored")
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreCD bool
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <branch-name>",
+	Short: "Restore a removed worktree from the trash",
+	Long: `Recreate the worktree of the most recent archive of a branch and reapply the
+uncommitted changes and untracked files it had when it was removed.
+Restored changes are left unstaged. The branch is recreated at its archived
+tip if it was deleted. See 'giwo trash list' for the available archives.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArchivedBranches,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branchName := args[0]
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		fmt.Printf("♻️  Restoring worktree '%s'...\n", branchName)
+
+		a, err := manager.Restore(cmd.Context(), branchName)
+		if err != nil {
+			return fmt.Errorf("failed to restore worktree: %w", err)
+		}
+
+		fmt.Printf("📦 Restored archive from %s\n", a.Age())
+		return announceWorktree(manager, branchName, restoreCD)
+	},
+}
+
+// completeArchivedBranches completes the first argument with the branches
+// that have archives in the trash.
+func completeArchivedBranches(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := newManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	archives, err := manager.Archives(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []cobra.Completion
+	seen := make(map[string]bool)
+	for _, a := range archives {
+		if !seen[a.Branch] && strings.HasPrefix(a.Branch, toComplete) {
+			seen[a.Branch] = true
+			completions = append(completions, cobra.CompletionWithDesc(a.Branch, "archived "+a.Age()))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	restoreCmd.Flags().BoolVar(&restoreCD, "cd", false, "Change into the restored worktree (requires shell integration)")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewForce bool
+	reviewCD    bool
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review <pr-number>",
+	Short: "Create a worktree for a pull request",
+	Long: `Check out a pull request (a merge request on GitLab) into .worktree/pr-<number>.
+The pull request head is fetched from the ref the forge publishes, such as
+refs/pull/<number>/head, so pull requests opened from forks work too.
+This is equivalent to 'giwo create --pr <number>'.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstPullRequest,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		number, err := parsePullRequestNumber(args[0])
+		if err != nil {
+			return err
+		}
+		return createPullRequestWorktree(cmd, number, reviewForce, reviewCD)
+	},
+}
+
+// createPullRequestWorktree resolves pull request number and creates its worktree.
+func createPullRequestWorktree(cmd *cobra.Command, number int, force, cd bool) error {
+	ctx := cmd.Context()
+	manager, err := newManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize manager: %w", err)
+	}
+	applyBootstrapFlag(cmd, manager)
+
+	src := worktree.PullRequestSource{Number: number}
+
+	// Resolve the pull request for display and hooks. Forges that publish
+	// pull request refs do not need the API to fetch the head, so failures
+	// here are only fatal for forges that do not.
+	f, err := manager.Forge()
+	if err != nil {
+		fmt.Printf("⚠️  Warning: %v; assuming GitHub-style pull request refs\n", err)
+	} else {
+		src.Ref = f.PullRequestRef(number)
+
+		pr, err := f.PullRequest(ctx, number)
+		switch {
+		case err == nil:
+			src.Base = pr.BaseRef
+			fmt.Printf("🔍 #%d %s (%s → %s)\n", pr.Number, pr.Title, pr.HeadRef, pr.BaseRef)
+			if src.Ref == "" {
+				src.Ref = "refs/heads/" + pr.HeadRef
+				if pr.FromFork {
+					src.Remote = pr.HeadCloneURL
+				}
+			}
+		case src.Ref == "":
+			return fmt.Errorf("failed to look up pull request #%d: %w", number, err)
+		default:
+			fmt.Printf("⚠️  Warning: failed to look up pull request #%d: %v\n", number, err)
+		}
+	}
+
+	branchName := worktree.PullRequestBranch(number)
+	fmt.Printf("🌱 Creating worktree '%s' for pull request #%d...\n", branchName, number)
+
+	ctx, stop := interruptible(ctx)
+	defer stop()
+	if err := manager.CreateFromPullRequest(ctx, src, force); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	return announceWorktree(manager, branchName, cd)
+}
+
+// parsePullRequestNumber parses "1234" or "#1234" into a pull request number.
+func parsePullRequestNumber(s string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid pull request number: %s", s)
+	}
+	return number, nil
+}
+
+func init() {
+	reviewCmd.Flags().BoolVar(&reviewForce, "force", false, "Force creation even if directory exists")
+	reviewCmd.Flags().BoolVar(&reviewCD, "cd", false, "Change into the new worktree (requires shell integration)")
+	reviewCmd.Flags().Bool("bootstrap", false, "Install dependencies or reuse them from other worktrees (default: bootstrap.enabled setting)")
+}
@@ -1,12 +1,21 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
+// traceGit makes every git invocation be logged to stderr.
+var traceGit bool
+
 var rootCmd = &cobra.Command{
 	Use:   "giwo",
 	Short: "Git WorkTree Manager - Efficiently manage Git worktrees",
@@ -22,7 +31,33 @@ func Execute() {
 	}
 }
 
+// newManager creates a worktree manager for the current repository, tracing
+// git invocations when --trace-git is set.
+func newManager() (*worktree.Manager, error) {
+	var opts []worktree.Option
+	if traceGit {
+		opts = append(opts, worktree.WithGitRunner(worktree.NewTracingRunner(worktree.ExecRunner{}, os.Stderr)))
+	}
+	return worktree.New(opts...)
+}
+
+// interruptible returns a context that is canceled on Ctrl-C instead of the
+// process being killed, so that the operation can roll itself back.
+func interruptible(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+}
+
+// confirm asks a yes/no question on stdin and reports whether the answer was yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(response)) == "y"
+}
+
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&traceGit, "trace-git", os.Getenv("GIWO_TRACE_GIT") != "", "Log every git command to stderr (also enabled by GIWO_TRACE_GIT)")
+
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(listCmd)
@@ -30,4 +65,15 @@ func init() {
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(pruneCmd)
 	rootCmd.AddCommand(switchCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(shellInitCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(bootstrapCmd)
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// cdFileEnv names the file that the shell wrapper installed by 'giwo
+// shell-init' reads the directory to change to from once giwo exits.
+const cdFileEnv = "GIWO_CD_FILE"
+
+// shellScripts holds the integration script of each supported shell.
+var shellScripts = map[string]string{
+	"bash": posixShellScript + "\nsource <(command giwo completion bash)\n",
+	"zsh":  posixShellScript + "\nif (( $+functions[compdef] )); then\n  source <(command giwo completion zsh)\nfi\n",
+	"fish": fishShellScript,
+}
+
+// posixShellScript defines the giwo wrapper function for bash and zsh.
+const posixShellScript = `# giwo shell integration
+giwo() {
+  local cd_file ret
+  cd_file="$(mktemp -t giwo-cd.XXXXXX)" || return 1
+  GIWO_CD_FILE="$cd_file" command giwo "$@"
+  ret=$?
+  if [ -s "$cd_file" ]; then
+    cd -- "$(cat -- "$cd_file")" || ret=$?
+  fi
+  rm -f -- "$cd_file"
+  return $ret
+}
+`
+
+// fishShellScript defines the giwo wrapper function for fish.
+const fishShellScript = `# giwo shell integration
+function giwo --description 'Git WorkTree Manager'
+    set -l cd_file (mktemp -t giwo-cd.XXXXXX); or return 1
+    GIWO_CD_FILE=$cd_file command giwo $argv
+    set -l ret $status
+    if test -s $cd_file
+        cd (cat $cd_file); or set ret $status
+    end
+    rm -f $cd_file
+    return $ret
+end
+
+command giwo completion fish | source
+`
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print shell integration code",
+	Long: `Print a giwo wrapper function and completion setup for your shell.
+With the wrapper, 'giwo switch', 'giwo create --cd' and removing the current
+worktree with 'giwo remove' change the directory of your shell.
+
+Add one of the following to your shell configuration:
+
+  eval "$(giwo shell-init bash)"    # ~/.bashrc
+  eval "$(giwo shell-init zsh)"     # ~/.zshrc
+  giwo shell-init fish | source     # ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		script, ok := shellScripts[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish)", args[0])
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+// requestShellCD asks the shell wrapper to change to dir once giwo exits.
+// It reports false when giwo does not run inside the wrapper.
+func requestShellCD(dir string) (bool, error) {
+	cdFile := os.Getenv(cdFileEnv)
+	if cdFile == "" {
+		return false, nil
+	}
+	if err := os.WriteFile(cdFile, []byte(dir), 0o600); err != nil {
+		return false, fmt.Errorf("failed to hand directory to shell: %w", err)
+	}
+	return true, nil
+}
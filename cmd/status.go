@@ -12,7 +12,7 @@ var statusCmd = &cobra.Command{
 	Short: "Show worktree statistics",
 	Long:  `Display statistics about worktrees and provide recommended actions.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
@@ -38,8 +38,8 @@ var statusCmd = &cobra.Command{
 		mergedBranches, err := manager.GetMergedBranches(ctx)
 		if err == nil && len(mergedBranches) > 0 {
 			fmt.Printf("\n🧹 %d merged branch(es) can be cleaned up:\n", len(mergedBranches))
-			for _, branch := range mergedBranches {
-				fmt.Printf("  - %s\n", branch)
+			for _, merged := range mergedBranches {
+				fmt.Printf("  - %s (%s)\n", merged.Branch, merged.Description())
 			}
 			fmt.Printf("\n💡 Run 'gwt clean' to remove merged worktrees\n")
 		}
@@ -64,7 +64,7 @@ func calculateStats(worktrees []*worktree.Worktree) worktree.Stats {
 			stats.Active++
 		}
 
-		if !wt.IsClean {
+		if !wt.IsClean && wt.Error == "" {
 			stats.Dirty++
 		}
 	}
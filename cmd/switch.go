@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -23,14 +24,15 @@ var switchCmd = &cobra.Command{
 	Long: `Switch to a worktree using an interactive fuzzy search interface.
 By default, shows all worktrees with real-time incremental filtering.
 Use --selector for the classic numbered list interface instead.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runSwitchCommand,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeBranches,
+	RunE:              runSwitchCommand,
 }
 
 func runSwitchCommand(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
-	manager, err := worktree.New()
+	manager, err := newManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize manager: %w", err)
 	}
@@ -77,8 +79,29 @@ func runSwitchCommand(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	return switchToWorktree(ctx, manager, selected, switchPrint)
+}
+
+// switchToWorktree records the access to selected, runs the post-switch
+// hooks and moves the shell into it. With printOnly set it only prints the
+// path of selected.
+func switchToWorktree(ctx context.Context, manager *worktree.Manager, selected *worktree.Worktree, printOnly bool) error {
+	if selected.Branch != "" {
+		if err := manager.MarkAccessed(ctx, selected.Branch); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record access: %v\n", err)
+		}
+	}
+
+	hc := worktree.HookContext{Branch: selected.Branch, Path: selected.Path}
+	if selected.Metadata != nil {
+		hc.Ports = selected.Metadata.Ports
+	}
+	if err := manager.RunHooks(ctx, worktree.HookPostSwitch, hc); err != nil {
+		return fmt.Errorf("failed to switch worktree: %w", err)
+	}
+
 	// If --print flag is set, just print the path
-	if switchPrint {
+	if printOnly {
 		fmt.Println(selected.Path)
 		return nil
 	}
@@ -90,12 +113,16 @@ func runSwitchCommand(cmd *cobra.Command, args []string) error {
 	}
 
 	if currentDir == selected.Path {
-		fmt.Printf("Already in worktree '%s'\n", selected.Branch)
+		fmt.Printf("Already in worktree '%s'\n", selected.Name())
 		return nil
 	}
 
-	// Try to change directory using a subshell
-	fmt.Printf("🔄 Switching to worktree '%s' at %s\n", selected.Branch, selected.Path)
+	fmt.Printf("🔄 Switching to worktree '%s' at %s\n", selected.Name(), selected.Path)
+
+	// The shell wrapper from 'giwo shell-init' changes the directory for us
+	if ok, err := requestShellCD(selected.Path); err != nil || ok {
+		return err
+	}
 
 	// Since we can't change the parent shell's directory from a child process,
 	// we'll provide instructions to the user
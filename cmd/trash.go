@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashPurgeAll       bool
+	trashPurgeOlderThan string
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage archives of removed worktrees",
+	Long: `Before a worktree is removed, giwo archives its uncommitted changes, untracked
+files and branch tip under refs/giwo/archive/. Archives are kept for the
+trash.retention period (30 days by default) and can be brought back with
+'giwo restore <branch>'.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List archives of removed worktrees",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		archives, err := manager.Archives(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list archives: %w", err)
+		}
+
+		if len(archives) == 0 {
+			fmt.Println("🗑️  Trash is empty")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		defer w.Flush()
+
+		fmt.Fprintf(w, "BRANCH\tARCHIVED\tEXPIRES\tCHANGES\tHEAD\n")
+		for _, a := range archives {
+			changes := "clean"
+			if a.Dirty {
+				changes = "uncommitted"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				a.Branch, a.Age(), a.ExpiresAt(manager.TrashRetention()).Format(time.DateOnly),
+				changes, a.Head[:min(7, len(a.Head))])
+		}
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete expired archives",
+	Long: `Delete archives older than the trash.retention period.
+Use --older-than to pick another age, or --all to empty the trash.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		cutoff := time.Now().Add(-manager.TrashRetention())
+		switch {
+		case trashPurgeAll:
+			cutoff = time.Now()
+		case trashPurgeOlderThan != "":
+			age, err := config.ParseDuration(trashPurgeOlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+			cutoff = time.Now().Add(-age)
+		}
+
+		purged, err := manager.PurgeArchives(cmd.Context(), cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to purge archives: %w", err)
+		}
+
+		if len(purged) == 0 {
+			fmt.Println("🗑️  No archives to purge")
+			return nil
+		}
+		for _, a := range purged {
+			fmt.Printf("  - %s (archived %s)\n", a.Branch, a.Age())
+		}
+		fmt.Printf("✅ Purged %d archive(s)\n", len(purged))
+		return nil
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().BoolVar(&trashPurgeAll, "all", false, "Delete all archives")
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "", "Delete archives older than this age, such as 72h or 7d")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+}
@@ -4,17 +4,30 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Sentinel errors following the style guide.
 var (
-	ErrNotGitRepository     = errors.New("not in a git repository")
-	ErrWorktreeExists       = errors.New("worktree already exists")
-	ErrWorktreeNotFound     = errors.New("worktree not found")
-	ErrBranchNotFound       = errors.New("branch not found")
-	ErrInvalidBranchName    = errors.New("invalid branch name")
-	ErrGitHubAPIUnavailable = errors.New("github API unavailable")
-	ErrOperationCancelled   = errors.New("operation cancelled by user")
+	ErrNotGitRepository    = errors.New("not in a git repository")
+	ErrWorktreeExists      = errors.New("worktree already exists")
+	ErrWorktreeNotFound    = errors.New("worktree not found")
+	ErrBranchNotFound      = errors.New("branch not found")
+	ErrInvalidBranchName   = errors.New("invalid branch name")
+	ErrPullRequestNotFound = errors.New("pull request not found")
+	ErrForgeAPIUnavailable = errors.New("forge API unavailable")
+	ErrUnsupportedForge    = errors.New("unsupported forge")
+	ErrOperationCancelled  = errors.New("operation cancelled by user")
+	ErrInvalidConfig       = errors.New("invalid configuration")
+	ErrHookTimeout         = errors.New("hook timed out")
+	ErrUnsafeRemoval       = errors.New("unsafe to remove")
+	ErrArchiveNotFound     = errors.New("archive not found")
+	ErrBranchExists        = errors.New("branch already exists")
+	ErrBranchCheckedOut    = errors.New("branch already checked out")
+	ErrInvalidRevision     = errors.New("invalid revision")
+	ErrWorktreeLocked      = errors.New("worktree already locked")
+	ErrWorktreeNotLocked   = errors.New("worktree not locked")
+	ErrNoFreePorts         = errors.New("no free port block")
 )
 
 // ValidationError represents a validation error with details.
@@ -51,6 +64,65 @@ func (e *GitError) Unwrap() error {
 	return e.Err
 }
 
+// HookError represents a lifecycle hook that failed.
+type HookError struct {
+	Event    string
+	Command  string
+	ExitCode int
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *HookError) Error() string {
+	if e.ExitCode > 0 {
+		return fmt.Sprintf("%s hook %q exited with status %d", e.Event, e.Command, e.ExitCode)
+	}
+	return fmt.Sprintf("%s hook %q failed: %v", e.Event, e.Command, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// RiskError reports a worktree removal refused because of risks the caller
+// did not accept.
+type RiskError struct {
+	Branch string
+
+	// Risks names the unaccepted risks, such as "uncommitted".
+	Risks []string
+
+	// Details describes each risk, in the same order as Risks.
+	Details []string
+}
+
+// Error implements the error interface.
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("unsafe to remove '%s': %s", e.Branch, strings.Join(e.Details, "; "))
+}
+
+// Unwrap returns ErrUnsafeRemoval.
+func (e *RiskError) Unwrap() error {
+	return ErrUnsafeRemoval
+}
+
+// CheckedOutError reports a branch that is already checked out in a worktree.
+type CheckedOutError struct {
+	Branch string
+	Path   string
+}
+
+// Error implements the error interface.
+func (e *CheckedOutError) Error() string {
+	return fmt.Sprintf("branch '%s' is already checked out at %s", e.Branch, e.Path)
+}
+
+// Unwrap returns ErrBranchCheckedOut.
+func (e *CheckedOutError) Unwrap() error {
+	return ErrBranchCheckedOut
+}
+
 // NewValidationError creates a new validation error.
 func NewValidationError(field, value string, err error) *ValidationError {
 	return &ValidationError{
@@ -68,3 +140,30 @@ func NewGitError(operation string, args []string, err error) *GitError {
 		Err:       err,
 	}
 }
+
+// NewRiskError creates a new risk error.
+func NewRiskError(branch string, risks, details []string) *RiskError {
+	return &RiskError{
+		Branch:  branch,
+		Risks:   risks,
+		Details: details,
+	}
+}
+
+// NewCheckedOutError creates a new checked out error.
+func NewCheckedOutError(branch, path string) *CheckedOutError {
+	return &CheckedOutError{
+		Branch: branch,
+		Path:   path,
+	}
+}
+
+// NewHookError creates a new hook error.
+func NewHookError(event, command string, exitCode int, err error) *HookError {
+	return &HookError{
+		Event:    event,
+		Command:  command,
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
@@ -78,3 +78,69 @@ func TestGitError(t *testing.T) {
 		})
 	}
 }
+
+func TestHookError(t *testing.T) {
+	for name, tt := range map[string]struct {
+		event    string
+		command  string
+		exitCode int
+		err      error
+		expected string
+	}{
+		"non-zero exit": {
+			event:    "post-create",
+			command:  "npm ci",
+			exitCode: 1,
+			err:      errors.New("exit status 1"),
+			expected: `post-create hook "npm ci" exited with status 1`,
+		},
+		"timeout": {
+			event:    "pre-remove",
+			command:  "make stop",
+			exitCode: -1,
+			err:      ErrHookTimeout,
+			expected: `pre-remove hook "make stop" failed: hook timed out`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewHookError(tt.event, tt.command, tt.exitCode, tt.err)
+
+			if diff := cmp.Diff(tt.expected, err.Error()); diff != "" {
+				t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error to wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestRiskError(t *testing.T) {
+	err := NewRiskError("feature-auth", []string{"uncommitted", "unpushed"},
+		[]string{"2 uncommitted changes", "3 commits not pushed to origin/feature-auth"})
+
+	expected := "unsafe to remove 'feature-auth': 2 uncommitted changes; 3 commits not pushed to origin/feature-auth"
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(err, ErrUnsafeRemoval) {
+		t.Errorf("Expected error to wrap %v", ErrUnsafeRemoval)
+	}
+}
+
+func TestCheckedOutError(t *testing.T) {
+	err := NewCheckedOutError("feature-auth", "/src/repo/.worktree/feature-auth")
+
+	expected := "branch 'feature-auth' is already checked out at /src/repo/.worktree/feature-auth"
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(err, ErrBranchCheckedOut) {
+		t.Errorf("Expected error to wrap %v", ErrBranchCheckedOut)
+	}
+}
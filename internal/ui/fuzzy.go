@@ -4,6 +4,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/knwoop/giwo/pkg/worktree"
 	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
@@ -36,7 +37,7 @@ func (f *FuzzyFinder) Search() (*worktree.Worktree, error) {
 	idx, err := fuzzyfinder.Find(
 		f.worktrees,
 		func(i int) string {
-			return f.worktrees[i].Branch
+			return f.worktrees[i].Name()
 		},
 		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
 			if i == -1 {
@@ -62,7 +63,11 @@ func (f *FuzzyFinder) formatWorktreePreview(wt *worktree.Worktree) string {
 	var lines []string
 
 	// Branch and path info
-	lines = append(lines, fmt.Sprintf("Branch: %s", wt.Branch))
+	if wt.Detached {
+		lines = append(lines, fmt.Sprintf("Detached at: %s", wt.Head))
+	} else {
+		lines = append(lines, fmt.Sprintf("Branch: %s", wt.Branch))
+	}
 	lines = append(lines, fmt.Sprintf("Path: %s", wt.Path))
 
 	// Status info
@@ -73,7 +78,15 @@ func (f *FuzzyFinder) formatWorktreePreview(wt *worktree.Worktree) string {
 	}
 
 	// Clean status
-	if wt.IsClean {
+	if wt.Prunable {
+		status := "Status: prunable 👻"
+		if wt.PrunableReason != "" {
+			status += fmt.Sprintf(" (%s)", wt.PrunableReason)
+		}
+		lines = append(lines, status)
+	} else if wt.Error != "" {
+		lines = append(lines, fmt.Sprintf("Status: unknown ❌ (%s)", wt.Error))
+	} else if wt.IsClean {
 		lines = append(lines, "Status: Clean ✅")
 	} else {
 		changes := wt.Added + wt.Modified + wt.Deleted
@@ -89,11 +102,45 @@ func (f *FuzzyFinder) formatWorktreePreview(wt *worktree.Worktree) string {
 		}
 	}
 
+	// Lock set with 'giwo lock'
+	if wt.Locked {
+		if wt.LockReason != "" {
+			lines = append(lines, fmt.Sprintf("Locked: %s 🔒", wt.LockReason))
+		} else {
+			lines = append(lines, "Locked 🔒")
+		}
+	}
+
 	// Remote sync status
 	if wt.Ahead > 0 || wt.Behind > 0 {
 		lines = append(lines, fmt.Sprintf("Sync: +%d/-%d commits 📡", wt.Ahead, wt.Behind))
 	}
 
+	// Notes recorded by giwo
+	if md := wt.Metadata; md != nil {
+		if md.Description != "" {
+			lines = append(lines, fmt.Sprintf("Note: %s 📝", md.Description))
+		}
+		if len(md.Tags) > 0 {
+			lines = append(lines, fmt.Sprintf("Tags: %s 🏷️", strings.Join(md.Tags, ", ")))
+		}
+		if md.Issue != "" {
+			lines = append(lines, fmt.Sprintf("Issue: %s", md.Issue))
+		}
+		if md.PullRequest != 0 {
+			lines = append(lines, fmt.Sprintf("Pull request: #%d", md.PullRequest))
+		}
+		if md.Base != "" {
+			lines = append(lines, fmt.Sprintf("Base: %s", md.Base))
+		}
+		if !md.CreatedAt.IsZero() {
+			lines = append(lines, fmt.Sprintf("Created: %s", md.CreatedAt.Format(time.DateTime)))
+		}
+		if !md.LastAccessedAt.IsZero() {
+			lines = append(lines, fmt.Sprintf("Last accessed: %s", md.LastAccessedAt.Format(time.DateTime)))
+		}
+	}
+
 	// Last commit info
 	if wt.LastCommit != "" {
 		lines = append(lines, fmt.Sprintf("Last commit: %s", wt.LastCommit))
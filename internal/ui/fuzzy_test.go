@@ -83,6 +83,51 @@ func TestFormatWorktreePreview(t *testing.T) {
 				"Commit age: 2h ago",
 			},
 		},
+		"locked worktree": {
+			worktree: &worktree.Worktree{
+				Branch:     "feature-usb",
+				Path:       "/mnt/usb/feature-usb",
+				IsClean:    true,
+				Locked:     true,
+				LockReason: "on a USB drive",
+			},
+			expected: []string{
+				"Status: Clean ✅",
+				"Locked: on a USB drive 🔒",
+			},
+		},
+		"prunable worktree": {
+			worktree: &worktree.Worktree{
+				Branch:         "feature-gone",
+				Path:           "/repo/.worktree/feature-gone",
+				Prunable:       true,
+				PrunableReason: "gitdir file points to non-existent location",
+			},
+			expected: []string{
+				"Status: prunable 👻 (gitdir file points to non-existent location)",
+			},
+		},
+		"worktree with notes": {
+			worktree: &worktree.Worktree{
+				Branch:  "feature-auth",
+				Path:    "/repo/.worktree/feature-auth",
+				IsClean: true,
+				Metadata: &worktree.Metadata{
+					Base:        "main",
+					Description: "Rework login flow",
+					Tags:        []string{"auth", "backend"},
+					Issue:       "PROJ-42",
+					PullRequest: 128,
+				},
+			},
+			expected: []string{
+				"Note: Rework login flow 📝",
+				"Tags: auth, backend",
+				"Issue: PROJ-42",
+				"Pull request: #128",
+				"Base: main",
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
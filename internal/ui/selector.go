@@ -41,7 +41,7 @@ func (s *Selector) Select() (*worktree.Worktree, error) {
 	// Display numbered list of worktrees
 	for i, wt := range s.worktrees {
 		status := s.formatWorktreeStatus(wt)
-		fmt.Printf("  %d) %s %s\n", i+1, wt.Branch, status)
+		fmt.Printf("  %d) %s %s\n", i+1, wt.Name(), status)
 	}
 
 	fmt.Println()
@@ -85,7 +85,7 @@ func (s *Selector) SelectWithFilter(filter string) (*worktree.Worktree, error) {
 	filter = strings.ToLower(filter)
 
 	for _, wt := range s.worktrees {
-		if strings.Contains(strings.ToLower(wt.Branch), filter) {
+		if strings.Contains(strings.ToLower(wt.Name()), filter) {
 			filtered = append(filtered, wt)
 		}
 	}
@@ -117,11 +117,19 @@ func (s *Selector) formatWorktreeStatus(wt *worktree.Worktree) string {
 		parts = append(parts, "🌱")
 	}
 
-	if !wt.IsClean {
+	if wt.Prunable {
+		parts = append(parts, "👻 prunable")
+	} else if wt.Error != "" {
+		parts = append(parts, "❌ status unknown")
+	} else if !wt.IsClean {
 		changes := wt.Added + wt.Modified + wt.Deleted
 		parts = append(parts, fmt.Sprintf("⚠️  %d changes", changes))
 	}
 
+	if wt.Locked {
+		parts = append(parts, "🔒 locked")
+	}
+
 	if wt.Ahead > 0 || wt.Behind > 0 {
 		parts = append(parts, fmt.Sprintf("📡 +%d/-%d", wt.Ahead, wt.Behind))
 	}
@@ -57,6 +57,24 @@ func TestFormatWorktreeStatus(t *testing.T) {
 			},
 			expected: "🌱 📡 +2/-1 📁 /repo/.worktree/feature",
 		},
+		"locked worktree": {
+			worktree: &worktree.Worktree{
+				Branch:     "feature",
+				Path:       "/mnt/usb/feature",
+				IsClean:    true,
+				Locked:     true,
+				LockReason: "on a USB drive",
+			},
+			expected: "🌱 🔒 locked 📁 /mnt/usb/feature",
+		},
+		"prunable worktree": {
+			worktree: &worktree.Worktree{
+				Branch:   "feature",
+				Path:     "/repo/.worktree/feature",
+				Prunable: true,
+			},
+			expected: "🌱 👻 prunable 📁 /repo/.worktree/feature",
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
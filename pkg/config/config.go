@@ -0,0 +1,707 @@
+// Package config loads giwo configuration from repository and user files.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// FetchPolicy controls when remotes are fetched before creating a worktree.
+type FetchPolicy string
+
+// Fetch policy constants.
+const (
+	// FetchAlways fetches all branches of the default remote.
+	FetchAlways FetchPolicy = "always"
+	// FetchBaseOnly fetches only the base branch of the new worktree.
+	FetchBaseOnly FetchPolicy = "base-only"
+	// FetchIfStale fetches like FetchAlways unless the last fetch is more
+	// recent than fetch_max_age.
+	FetchIfStale FetchPolicy = "if-stale"
+	// FetchNever works from the remote-tracking branches as they are.
+	FetchNever FetchPolicy = "never"
+)
+
+// FetchPolicies lists the valid fetch policies.
+var FetchPolicies = []FetchPolicy{FetchAlways, FetchBaseOnly, FetchIfStale, FetchNever}
+
+// Default values used when no configuration overrides them.
+const (
+	DefaultWorktreeDir = ".worktree"
+	DefaultRemote      = "origin"
+
+	// DefaultFetchMaxAge is how old the last fetch may be before the
+	// if-stale fetch policy fetches again.
+	DefaultFetchMaxAge = time.Hour
+
+	// DefaultTrashRetention is how long archives of removed worktrees are kept.
+	DefaultTrashRetention = 30 * 24 * time.Hour
+
+	// DefaultPortBase is the first port reserved for worktrees.
+	DefaultPortBase = 10000
+	// DefaultPortBlockSize is how many ports each worktree reserves.
+	DefaultPortBlockSize = 10
+)
+
+// PathPlaceholders lists the placeholders of worktree_path templates and
+// what they are replaced with.
+var PathPlaceholders = map[string]string{
+	"{repo}":   "name of the repository root directory",
+	"{owner}":  "owner of the repository on the default remote",
+	"{branch}": "branch name; slashes create nested directories",
+	"{slug}":   "branch name with slashes replaced by dashes",
+	"{root}":   "repository root directory",
+}
+
+// placeholderRegex matches the placeholders of a worktree_path template.
+var placeholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// DefaultCopyFiles lists the files copied from the main worktree by default.
+var DefaultCopyFiles = []string{
+	".editorconfig",
+	".env",
+	".env.local",
+	".gitignore",
+	".prettierrc",
+	".rgignore",
+}
+
+// DefaultProtectedBranches lists the branches that are never cleaned up by default.
+var DefaultProtectedBranches = []string{"main", "master", "develop", "dev"}
+
+// RepoConfigFiles lists the repository config file names, in lookup order.
+var RepoConfigFiles = []string{".giwo.toml", ".giwo.yaml", ".giwo.yml"}
+
+// UserConfigFiles lists the user config file names inside the giwo config
+// directory, in lookup order.
+var UserConfigFiles = []string{"config.toml", "config.yaml", "config.yml"}
+
+// Config holds the merged giwo configuration.
+type Config struct {
+	// WorktreeDir is where new worktrees are placed. Relative paths are
+	// resolved against the repository root.
+	WorktreeDir string `toml:"worktree_dir" yaml:"worktree_dir" json:"worktree_dir"`
+
+	// WorktreePath is a template for the path of new worktrees, such as
+	// "../{repo}-{slug}". It overrides WorktreeDir; see PathPlaceholders.
+	// Relative paths are resolved against the repository root.
+	WorktreePath string `toml:"worktree_path" yaml:"worktree_path" json:"worktree_path,omitempty"`
+
+	// CopyFiles lists the files and directories copied from the main
+	// worktree into new worktrees. Entries are paths relative to the
+	// repository root or glob patterns, where ** matches any number of
+	// directories. Patterns are matched against the files git ignores.
+	CopyFiles []string `toml:"copy_files" yaml:"copy_files" json:"copy_files"`
+
+	// SymlinkFiles is like CopyFiles, but its matches are linked to the main
+	// worktree instead of copied, so that secrets keep a single source.
+	SymlinkFiles []string `toml:"symlink_files" yaml:"symlink_files" json:"symlink_files,omitempty"`
+
+	// CopyExclude lists glob patterns of files that are neither copied nor
+	// linked, even inside directories matched by CopyFiles or SymlinkFiles.
+	CopyExclude []string `toml:"copy_exclude" yaml:"copy_exclude" json:"copy_exclude,omitempty"`
+
+	// ProtectedBranches lists branches that clean never removes.
+	ProtectedBranches []string `toml:"protected_branches" yaml:"protected_branches" json:"protected_branches"`
+
+	// DefaultRemote is the remote used for fetching and base branches.
+	DefaultRemote string `toml:"default_remote" yaml:"default_remote" json:"default_remote"`
+
+	// DefaultBase is the base branch used when create is given no --base.
+	// An empty value means the current branch.
+	DefaultBase string `toml:"default_base" yaml:"default_base" json:"default_base,omitempty"`
+
+	// Fetch is the fetch policy applied before creating a worktree.
+	Fetch FetchPolicy `toml:"fetch" yaml:"fetch" json:"fetch"`
+
+	// FetchMaxAge is how old the last fetch may be under the if-stale policy.
+	FetchMaxAge Duration `toml:"fetch_max_age" yaml:"fetch_max_age" json:"fetch_max_age"`
+
+	// Jobs limits how many worktrees are inspected concurrently when listing
+	// them. Zero means the number of CPUs.
+	Jobs int `toml:"jobs" yaml:"jobs" json:"jobs,omitempty"`
+
+	// Hooks lists the commands run at worktree lifecycle events.
+	Hooks Hooks `toml:"hooks" yaml:"hooks" json:"hooks"`
+
+	// Forge selects the Git hosting service of the default remote.
+	Forge Forge `toml:"forge" yaml:"forge" json:"forge"`
+
+	// Trash configures the archives kept of removed worktrees.
+	Trash Trash `toml:"trash" yaml:"trash" json:"trash"`
+
+	// Ports configures the ports reserved for the dev servers of worktrees.
+	Ports Ports `toml:"ports" yaml:"ports" json:"ports"`
+
+	// Env configures the environment 'giwo env' exports for worktrees.
+	Env Env `toml:"env" yaml:"env" json:"env"`
+
+	// Bootstrap configures how dependencies are installed in new worktrees.
+	Bootstrap Bootstrap `toml:"bootstrap" yaml:"bootstrap" json:"bootstrap"`
+
+	// Files lists the configuration files that were loaded, lowest precedence first.
+	Files []string `toml:"-" yaml:"-" json:"files,omitempty"`
+}
+
+// Forge configures the Git hosting service used for pull request features.
+// Tokens are read from environment variables such as GITHUB_TOKEN or
+// GITLAB_TOKEN and are never stored in configuration files.
+type Forge struct {
+	// Type is github, gitlab, gitea or bitbucket. Empty detects the type
+	// from the host of the default remote.
+	Type string `toml:"type" yaml:"type" json:"type,omitempty"`
+
+	// APIURL overrides the API endpoint derived from the remote host.
+	APIURL string `toml:"api_url" yaml:"api_url" json:"api_url,omitempty"`
+}
+
+// Trash configures the archives giwo takes of worktrees before removing them.
+type Trash struct {
+	// Retention is how long archives are kept before they are purged.
+	Retention Duration `toml:"retention" yaml:"retention" json:"retention"`
+}
+
+// Ports configures the blocks of ports giwo reserves for worktrees. Each
+// worktree gets Size consecutive ports, and the blocks are laid out from Base
+// on so that no two worktrees share a port.
+type Ports struct {
+	// Base is the first port of the first block.
+	Base int `toml:"base" yaml:"base" json:"base"`
+
+	// Size is the number of ports in a block.
+	Size int `toml:"block_size" yaml:"block_size" json:"block_size"`
+}
+
+// Env configures the environment variables giwo exports for worktrees on top
+// of its own, such as GIWO_BRANCH and COMPOSE_PROJECT_NAME.
+type Env struct {
+	// Vars maps variable names to values, which are rendered like template
+	// files, for example "app_{{ .Slug }}". They override giwo's own
+	// variables of the same name.
+	Vars map[string]string `toml:"vars" yaml:"vars" json:"vars,omitempty"`
+
+	// Envrc writes the environment to .envrc in new worktrees for direnv.
+	Envrc bool `toml:"envrc" yaml:"envrc" json:"envrc,omitempty"`
+}
+
+// BootstrapMode is how dependency directories are shared between worktrees.
+type BootstrapMode string
+
+// Bootstrap mode constants.
+const (
+	// BootstrapHardlink hardlinks the files of the directory, falling back
+	// to copies where links are not possible. Worktrees then share the
+	// contents of the files, so a file edited in place changes in all of them.
+	BootstrapHardlink BootstrapMode = "hardlink"
+	// BootstrapCopy copies the files of the directory.
+	BootstrapCopy BootstrapMode = "copy"
+)
+
+// BootstrapModes lists the valid bootstrap modes.
+var BootstrapModes = []BootstrapMode{BootstrapHardlink, BootstrapCopy}
+
+// Bootstrap configures the installation of dependencies in new worktrees.
+// Dependency directories such as node_modules are reused from a worktree
+// whose lockfile is identical, and installed otherwise.
+type Bootstrap struct {
+	// Enabled bootstraps every new worktree.
+	Enabled bool `toml:"enabled" yaml:"enabled" json:"enabled"`
+
+	// Mode is how reused directories are shared.
+	Mode BootstrapMode `toml:"mode" yaml:"mode" json:"mode"`
+
+	// Install overrides the install command of ecosystems by name, such as
+	// npm = "npm install".
+	Install map[string]string `toml:"install" yaml:"install" json:"install,omitempty"`
+}
+
+// envNameRegex matches valid environment variable names.
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		WorktreeDir:       DefaultWorktreeDir,
+		CopyFiles:         append([]string(nil), DefaultCopyFiles...),
+		ProtectedBranches: append([]string(nil), DefaultProtectedBranches...),
+		DefaultRemote:     DefaultRemote,
+		Fetch:             FetchAlways,
+		FetchMaxAge:       Duration(DefaultFetchMaxAge),
+		Trash:             Trash{Retention: Duration(DefaultTrashRetention)},
+		Ports:             Ports{Base: DefaultPortBase, Size: DefaultPortBlockSize},
+		Bootstrap:         Bootstrap{Mode: BootstrapHardlink},
+	}
+}
+
+// Load builds the configuration for the repository at repoRoot.
+// Sources are applied in order of increasing precedence: built-in defaults,
+// the user config file, the repository config file and GIWO_* environment variables.
+func Load(repoRoot string) (*Config, error) {
+	cfg := Default()
+
+	if dir, err := UserConfigDir(); err == nil {
+		if err := cfg.mergeFirst(dir, UserConfigFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.mergeFirst(repoRoot, RepoConfigFiles); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// UserConfigDir returns the directory holding the user-global config file.
+// It honors XDG_CONFIG_HOME and falls back to ~/.config/giwo.
+func UserConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "giwo"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "giwo"), nil
+}
+
+// Validate reports whether the configuration values are usable.
+func (c *Config) Validate() error {
+	if !slices.Contains(FetchPolicies, c.Fetch) {
+		return fmt.Errorf("%w: unknown fetch policy %q", errors.ErrInvalidConfig, c.Fetch)
+	}
+
+	if c.FetchMaxAge <= 0 {
+		return fmt.Errorf("%w: fetch_max_age must be positive", errors.ErrInvalidConfig)
+	}
+
+	if c.WorktreePath != "" {
+		for _, placeholder := range placeholderRegex.FindAllString(c.WorktreePath, -1) {
+			if _, ok := PathPlaceholders[placeholder]; !ok {
+				return fmt.Errorf("%w: worktree_path: unknown placeholder %s", errors.ErrInvalidConfig, placeholder)
+			}
+		}
+		if !strings.Contains(c.WorktreePath, "{branch}") && !strings.Contains(c.WorktreePath, "{slug}") {
+			return fmt.Errorf("%w: worktree_path must contain {branch} or {slug}", errors.ErrInvalidConfig)
+		}
+	}
+
+	if err := validatePatterns("copy_files", c.CopyFiles); err != nil {
+		return err
+	}
+	if err := validatePatterns("symlink_files", c.SymlinkFiles); err != nil {
+		return err
+	}
+	if err := validatePatterns("copy_exclude", c.CopyExclude); err != nil {
+		return err
+	}
+
+	if c.DefaultRemote == "" {
+		return fmt.Errorf("%w: default_remote must not be empty", errors.ErrInvalidConfig)
+	}
+
+	if c.Jobs < 0 {
+		return fmt.Errorf("%w: jobs must not be negative", errors.ErrInvalidConfig)
+	}
+
+	if err := c.Hooks.validate(); err != nil {
+		return err
+	}
+
+	switch c.Forge.Type {
+	case "", "github", "gitlab", "gitea", "bitbucket":
+	default:
+		return fmt.Errorf("%w: unknown forge type %q", errors.ErrInvalidConfig, c.Forge.Type)
+	}
+
+	if c.Trash.Retention <= 0 {
+		return fmt.Errorf("%w: trash.retention must be positive", errors.ErrInvalidConfig)
+	}
+
+	if c.Ports.Base < 1 || c.Ports.Size < 1 || c.Ports.Base+c.Ports.Size-1 > 65535 {
+		return fmt.Errorf("%w: ports: block of %d from %d is out of range", errors.ErrInvalidConfig, c.Ports.Size, c.Ports.Base)
+	}
+
+	for name := range c.Env.Vars {
+		if !envNameRegex.MatchString(name) {
+			return fmt.Errorf("%w: env.vars: invalid variable name %q", errors.ErrInvalidConfig, name)
+		}
+	}
+
+	if !slices.Contains(BootstrapModes, c.Bootstrap.Mode) {
+		return fmt.Errorf("%w: unknown bootstrap mode %q", errors.ErrInvalidConfig, c.Bootstrap.Mode)
+	}
+
+	return nil
+}
+
+// validatePatterns reports whether patterns are valid glob patterns inside
+// the repository for the setting key.
+func validatePatterns(key string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil || !filepath.IsLocal(filepath.FromSlash(pattern)) {
+			return fmt.Errorf("%w: %s: invalid pattern %q", errors.ErrInvalidConfig, key, pattern)
+		}
+	}
+	return nil
+}
+
+// ResolveWorktreeDir returns the absolute worktree directory for repoRoot.
+func (c *Config) ResolveWorktreeDir(repoRoot string) string {
+	dir := expandHome(c.WorktreeDir)
+	if dir == "" {
+		dir = DefaultWorktreeDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// PathVars are the values substituted into a worktree_path template.
+type PathVars struct {
+	Root   string
+	Repo   string
+	Owner  string
+	Branch string
+}
+
+// PathTemplate returns the effective worktree_path template, which defaults
+// to "{branch}" inside the worktree directory.
+func (c *Config) PathTemplate() string {
+	if c.WorktreePath != "" {
+		return c.WorktreePath
+	}
+	dir := c.WorktreeDir
+	if dir == "" {
+		dir = DefaultWorktreeDir
+	}
+	return filepath.Join(dir, "{branch}")
+}
+
+// ResolveWorktreePath returns the absolute path of the worktree described
+// by vars.
+func (c *Config) ResolveWorktreePath(vars PathVars) string {
+	return resolvePath(c.PathTemplate(), vars)
+}
+
+// ResolveWorktreeBase returns the absolute directory that the worktree_path
+// template puts all worktrees in, which is the deepest directory of its
+// literal prefix before {branch} or {slug}. Removing a worktree cleans up
+// the directories it leaves empty up to this one.
+func (c *Config) ResolveWorktreeBase(vars PathVars) string {
+	tmpl := c.PathTemplate()
+	for _, placeholder := range []string{"{branch}", "{slug}"} {
+		if i := strings.Index(tmpl, placeholder); i >= 0 {
+			tmpl = tmpl[:i]
+		}
+	}
+	return filepath.Dir(resolvePath(tmpl+"_", vars))
+}
+
+// resolvePath substitutes vars into tmpl and makes the result absolute.
+func resolvePath(tmpl string, vars PathVars) string {
+	path := strings.NewReplacer(
+		"{root}", vars.Root,
+		"{repo}", vars.Repo,
+		"{owner}", vars.Owner,
+		"{branch}", vars.Branch,
+		"{slug}", strings.ReplaceAll(vars.Branch, "/", "-"),
+	).Replace(tmpl)
+
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(vars.Root, path)
+	}
+	return filepath.Clean(path)
+}
+
+// IsProtected returns true if the branch should not be automatically removed.
+func (c *Config) IsProtected(branch string) bool {
+	for _, p := range c.ProtectedBranches {
+		if branch == p {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeFirst merges the first existing file among names inside dir.
+func (c *Config) mergeFirst(dir string, names []string) error {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+
+		var fileCfg Config
+		var scalars fileScalars
+		if err := decode(path, data, &fileCfg, &scalars); err != nil {
+			return fmt.Errorf("%w: %s: %v", errors.ErrInvalidConfig, path, err)
+		}
+
+		c.merge(&fileCfg, &scalars)
+		c.Files = append(c.Files, path)
+		return nil
+	}
+	return nil
+}
+
+// fileScalars holds the scalar keys of a config file. Unlike the fields of
+// Config they are nil when the file does not set them, so that a file can
+// set what a file of lower precedence set back to the zero value, such as
+// jobs = 0 or default_base = "".
+type fileScalars struct {
+	WorktreeDir   *string      `toml:"worktree_dir" yaml:"worktree_dir"`
+	WorktreePath  *string      `toml:"worktree_path" yaml:"worktree_path"`
+	DefaultRemote *string      `toml:"default_remote" yaml:"default_remote"`
+	DefaultBase   *string      `toml:"default_base" yaml:"default_base"`
+	Fetch         *FetchPolicy `toml:"fetch" yaml:"fetch"`
+	FetchMaxAge   *Duration    `toml:"fetch_max_age" yaml:"fetch_max_age"`
+	Jobs          *int         `toml:"jobs" yaml:"jobs"`
+	Hooks         struct {
+		Timeout *Duration `toml:"timeout" yaml:"timeout"`
+	} `toml:"hooks" yaml:"hooks"`
+	Forge struct {
+		Type   *string `toml:"type" yaml:"type"`
+		APIURL *string `toml:"api_url" yaml:"api_url"`
+	} `toml:"forge" yaml:"forge"`
+	Trash struct {
+		Retention *Duration `toml:"retention" yaml:"retention"`
+	} `toml:"trash" yaml:"trash"`
+	Ports struct {
+		Base *int `toml:"base" yaml:"base"`
+		Size *int `toml:"block_size" yaml:"block_size"`
+	} `toml:"ports" yaml:"ports"`
+	Env struct {
+		Envrc *bool `toml:"envrc" yaml:"envrc"`
+	} `toml:"env" yaml:"env"`
+	Bootstrap struct {
+		Enabled *bool          `toml:"enabled" yaml:"enabled"`
+		Mode    *BootstrapMode `toml:"mode" yaml:"mode"`
+	} `toml:"bootstrap" yaml:"bootstrap"`
+}
+
+// merge overrides the lists and maps of c that other sets and the scalars
+// set in scalars.
+func (c *Config) merge(other *Config, scalars *fileScalars) {
+	setIf(&c.WorktreeDir, scalars.WorktreeDir)
+	setIf(&c.WorktreePath, scalars.WorktreePath)
+	if other.CopyFiles != nil {
+		c.CopyFiles = other.CopyFiles
+	}
+	if other.SymlinkFiles != nil {
+		c.SymlinkFiles = other.SymlinkFiles
+	}
+	if other.CopyExclude != nil {
+		c.CopyExclude = other.CopyExclude
+	}
+	if other.ProtectedBranches != nil {
+		c.ProtectedBranches = other.ProtectedBranches
+	}
+	setIf(&c.DefaultRemote, scalars.DefaultRemote)
+	setIf(&c.DefaultBase, scalars.DefaultBase)
+	setIf(&c.Fetch, scalars.Fetch)
+	setIf(&c.FetchMaxAge, scalars.FetchMaxAge)
+	setIf(&c.Jobs, scalars.Jobs)
+	c.Hooks.merge(&other.Hooks)
+	setIf(&c.Hooks.Timeout, scalars.Hooks.Timeout)
+	setIf(&c.Forge.Type, scalars.Forge.Type)
+	setIf(&c.Forge.APIURL, scalars.Forge.APIURL)
+	setIf(&c.Trash.Retention, scalars.Trash.Retention)
+	setIf(&c.Ports.Base, scalars.Ports.Base)
+	setIf(&c.Ports.Size, scalars.Ports.Size)
+	if len(other.Env.Vars) > 0 {
+		vars := maps.Clone(c.Env.Vars)
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		maps.Copy(vars, other.Env.Vars)
+		c.Env.Vars = vars
+	}
+	setIf(&c.Env.Envrc, scalars.Env.Envrc)
+	setIf(&c.Bootstrap.Enabled, scalars.Bootstrap.Enabled)
+	setIf(&c.Bootstrap.Mode, scalars.Bootstrap.Mode)
+	if len(other.Bootstrap.Install) > 0 {
+		install := maps.Clone(c.Bootstrap.Install)
+		if install == nil {
+			install = make(map[string]string)
+		}
+		maps.Copy(install, other.Bootstrap.Install)
+		c.Bootstrap.Install = install
+	}
+}
+
+// setIf sets *dst to *v unless v is nil.
+func setIf[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// applyEnv overrides fields from GIWO_* environment variables.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	if v, ok := lookup("GIWO_WORKTREE_DIR"); ok && v != "" {
+		c.WorktreeDir = v
+	}
+	if v, ok := lookup("GIWO_WORKTREE_PATH"); ok && v != "" {
+		c.WorktreePath = v
+	}
+	if v, ok := lookup("GIWO_COPY_FILES"); ok {
+		c.CopyFiles = splitList(v)
+	}
+	if v, ok := lookup("GIWO_SYMLINK_FILES"); ok {
+		c.SymlinkFiles = splitList(v)
+	}
+	if v, ok := lookup("GIWO_COPY_EXCLUDE"); ok {
+		c.CopyExclude = splitList(v)
+	}
+	if v, ok := lookup("GIWO_PROTECTED_BRANCHES"); ok {
+		c.ProtectedBranches = splitList(v)
+	}
+	if v, ok := lookup("GIWO_DEFAULT_REMOTE"); ok && v != "" {
+		c.DefaultRemote = v
+	}
+	if v, ok := lookup("GIWO_DEFAULT_BASE"); ok && v != "" {
+		c.DefaultBase = v
+	}
+	if v, ok := lookup("GIWO_FETCH"); ok && v != "" {
+		c.Fetch = FetchPolicy(v)
+	}
+	if v, ok := lookup("GIWO_FETCH_MAX_AGE"); ok && v != "" {
+		if err := c.FetchMaxAge.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("%w: GIWO_FETCH_MAX_AGE: %v", errors.ErrInvalidConfig, err)
+		}
+	}
+	if v, ok := lookup("GIWO_JOBS"); ok && v != "" {
+		jobs, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: GIWO_JOBS: %q is not a number", errors.ErrInvalidConfig, v)
+		}
+		c.Jobs = jobs
+	}
+	if v, ok := lookup("GIWO_FORGE_TYPE"); ok && v != "" {
+		c.Forge.Type = v
+	}
+	if v, ok := lookup("GIWO_FORGE_API_URL"); ok && v != "" {
+		c.Forge.APIURL = v
+	}
+	if v, ok := lookup("GIWO_TRASH_RETENTION"); ok && v != "" {
+		if err := c.Trash.Retention.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("%w: GIWO_TRASH_RETENTION: %v", errors.ErrInvalidConfig, err)
+		}
+	}
+	if v, ok := lookup("GIWO_PORTS_BASE"); ok && v != "" {
+		base, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: GIWO_PORTS_BASE: %q is not a number", errors.ErrInvalidConfig, v)
+		}
+		c.Ports.Base = base
+	}
+	if v, ok := lookup("GIWO_PORTS_BLOCK_SIZE"); ok && v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: GIWO_PORTS_BLOCK_SIZE: %q is not a number", errors.ErrInvalidConfig, v)
+		}
+		c.Ports.Size = size
+	}
+	if v, ok := lookup("GIWO_ENVRC"); ok && v != "" {
+		envrc, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%w: GIWO_ENVRC: %q is not a boolean", errors.ErrInvalidConfig, v)
+		}
+		c.Env.Envrc = envrc
+	}
+	if v, ok := lookup("GIWO_BOOTSTRAP"); ok && v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%w: GIWO_BOOTSTRAP: %q is not a boolean", errors.ErrInvalidConfig, v)
+		}
+		c.Bootstrap.Enabled = enabled
+	}
+	if v, ok := lookup("GIWO_BOOTSTRAP_MODE"); ok && v != "" {
+		c.Bootstrap.Mode = BootstrapMode(v)
+	}
+	return nil
+}
+
+// decode parses data according to the extension of path into cfg and the
+// scalar keys it sets into scalars.
+func decode(path string, data []byte, cfg *Config, scalars *fileScalars) error {
+	switch filepath.Ext(path) {
+	case ".toml":
+		md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(cfg)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+		_, err = toml.NewDecoder(bytes.NewReader(data)).Decode(scalars)
+		return err
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && err != io.EOF {
+			return err
+		}
+		if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(scalars); err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported config format %q", filepath.Ext(path))
+	}
+}
+
+// splitList splits a comma-separated list and drops empty entries.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,324 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestLoad(t *testing.T) {
+	for name, tt := range map[string]struct {
+		userFile string
+		userData string
+		repoFile string
+		repoData string
+		env      map[string]string
+		expected *Config
+	}{
+		"defaults only": {
+			expected: Default(),
+		},
+		"repo toml overrides defaults": {
+			repoFile: ".giwo.toml",
+			repoData: "worktree_dir = \"../wt\"\nprotected_branches = [\"trunk\"]\nfetch = \"never\"\n\n[ports]\nbase = 20000\n",
+			expected: &Config{
+				WorktreeDir:       "../wt",
+				CopyFiles:         DefaultCopyFiles,
+				ProtectedBranches: []string{"trunk"},
+				DefaultRemote:     "origin",
+				Fetch:             FetchNever,
+				FetchMaxAge:       Duration(DefaultFetchMaxAge),
+				Trash:             Trash{Retention: Duration(DefaultTrashRetention)},
+				Ports:             Ports{Base: 20000, Size: DefaultPortBlockSize},
+				Bootstrap:         Bootstrap{Mode: BootstrapHardlink},
+			},
+		},
+		"repo yaml overrides user toml": {
+			userFile: "config.toml",
+			userData: "default_remote = \"upstream\"\ndefault_base = \"develop\"\n\n[env.vars]\nDATABASE = \"app\"\nREDIS_DB = \"1\"\n\n[bootstrap]\nenabled = true\n\n[bootstrap.install]\nnpm = \"npm ci --ignore-scripts\"\ngo = \"go mod vendor\"\n",
+			repoFile: ".giwo.yaml",
+			repoData: "default_base: release\ncopy_files: [.vscode, \"config/**/*.yml\"]\nsymlink_files: [.env]\ncopy_exclude: [\"**/cache\"]\nenv:\n  vars:\n    DATABASE: \"app_{{ .Slug }}\"\n  envrc: true\nbootstrap:\n  mode: copy\n  install:\n    npm: npm install\n",
+			expected: &Config{
+				WorktreeDir:       DefaultWorktreeDir,
+				CopyFiles:         []string{".vscode", "config/**/*.yml"},
+				SymlinkFiles:      []string{".env"},
+				CopyExclude:       []string{"**/cache"},
+				ProtectedBranches: DefaultProtectedBranches,
+				DefaultRemote:     "upstream",
+				DefaultBase:       "release",
+				Fetch:             FetchAlways,
+				FetchMaxAge:       Duration(DefaultFetchMaxAge),
+				Trash:             Trash{Retention: Duration(DefaultTrashRetention)},
+				Ports:             Ports{Base: DefaultPortBase, Size: DefaultPortBlockSize},
+				Env: Env{
+					Vars:  map[string]string{"DATABASE": "app_{{ .Slug }}", "REDIS_DB": "1"},
+					Envrc: true,
+				},
+				Bootstrap: Bootstrap{
+					Enabled: true,
+					Mode:    BootstrapCopy,
+					Install: map[string]string{"npm": "npm install", "go": "go mod vendor"},
+				},
+			},
+		},
+		"repo config turns off user settings": {
+			userFile: "config.toml",
+			userData: "[env]\nenvrc = true\n\n[bootstrap]\nenabled = true\n",
+			repoFile: ".giwo.yml",
+			repoData: "env:\n  envrc: false\nbootstrap:\n  enabled: false\n",
+			expected: Default(),
+		},
+		"repo config resets user settings": {
+			userFile: "config.toml",
+			userData: "default_base = \"develop\"\njobs = 4\n\n[forge]\ntype = \"gitlab\"\n\n[hooks]\ntimeout = \"10s\"\n",
+			repoFile: ".giwo.yaml",
+			repoData: "default_base: \"\"\njobs: 0\nforge:\n  type: \"\"\nhooks:\n  timeout: 0s\n",
+			expected: Default(),
+		},
+		"env turns off files": {
+			repoFile: ".giwo.toml",
+			repoData: "[env]\nenvrc = true\n\n[bootstrap]\nenabled = true\n",
+			env: map[string]string{
+				"GIWO_ENVRC":     "false",
+				"GIWO_BOOTSTRAP": "0",
+			},
+			expected: Default(),
+		},
+		"env overrides files": {
+			repoFile: ".giwo.toml",
+			repoData: "default_base = \"develop\"\n",
+			env: map[string]string{
+				"GIWO_DEFAULT_BASE":       "main",
+				"GIWO_PROTECTED_BRANCHES": "main, prod,",
+				"GIWO_COPY_FILES":         "",
+				"GIWO_SYMLINK_FILES":      ".env, .env.local",
+				"GIWO_JOBS":               "4",
+				"GIWO_TRASH_RETENTION":    "7d",
+				"GIWO_FETCH":              "if-stale",
+				"GIWO_FETCH_MAX_AGE":      "10m",
+				"GIWO_PORTS_BLOCK_SIZE":   "4",
+				"GIWO_ENVRC":              "1",
+				"GIWO_BOOTSTRAP":          "true",
+			},
+			expected: &Config{
+				WorktreeDir:       DefaultWorktreeDir,
+				CopyFiles:         []string{},
+				SymlinkFiles:      []string{".env", ".env.local"},
+				ProtectedBranches: []string{"main", "prod"},
+				DefaultRemote:     "origin",
+				DefaultBase:       "main",
+				Fetch:             FetchIfStale,
+				FetchMaxAge:       Duration(10 * time.Minute),
+				Jobs:              4,
+				Trash:             Trash{Retention: Duration(7 * 24 * time.Hour)},
+				Ports:             Ports{Base: DefaultPortBase, Size: 4},
+				Env:               Env{Envrc: true},
+				Bootstrap:         Bootstrap{Enabled: true, Mode: BootstrapHardlink},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			userHome := t.TempDir()
+			repoRoot := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", userHome)
+			for _, key := range []string{"GIWO_WORKTREE_DIR", "GIWO_COPY_FILES", "GIWO_SYMLINK_FILES", "GIWO_COPY_EXCLUDE", "GIWO_PROTECTED_BRANCHES", "GIWO_DEFAULT_REMOTE", "GIWO_DEFAULT_BASE", "GIWO_FETCH", "GIWO_FETCH_MAX_AGE", "GIWO_JOBS", "GIWO_TRASH_RETENTION", "GIWO_PORTS_BASE", "GIWO_PORTS_BLOCK_SIZE", "GIWO_ENVRC", "GIWO_BOOTSTRAP", "GIWO_BOOTSTRAP_MODE"} {
+				if value, ok := tt.env[key]; ok {
+					t.Setenv(key, value)
+				} else {
+					t.Setenv(key, "")
+					os.Unsetenv(key)
+				}
+			}
+
+			if tt.userFile != "" {
+				writeFile(t, filepath.Join(userHome, "giwo", tt.userFile), tt.userData)
+			}
+			if tt.repoFile != "" {
+				writeFile(t, filepath.Join(repoRoot, tt.repoFile), tt.repoData)
+			}
+
+			cfg, err := Load(repoRoot)
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, cfg, cmpopts.IgnoreFields(Config{}, "Files")); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoRoot := t.TempDir()
+	writeFile(t, filepath.Join(repoRoot, ".giwo.toml"), `
+[hooks]
+timeout = "2m"
+
+[[hooks.post_create]]
+run = "npm ci"
+timeout = "10m"
+
+[[hooks.post_create]]
+run = "make generate"
+`)
+
+	cfg, err := Load(repoRoot)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	expected := []Hook{
+		{Run: "npm ci", Timeout: Duration(10 * time.Minute)},
+		{Run: "make generate"},
+	}
+	if diff := cmp.Diff(expected, cfg.Hooks.PostCreate); diff != "" {
+		t.Errorf("PostCreate mismatch (-want +got):\n%s", diff)
+	}
+
+	for i, want := range []time.Duration{10 * time.Minute, 2 * time.Minute} {
+		if diff := cmp.Diff(want, cfg.Hooks.TimeoutFor(cfg.Hooks.PostCreate[i])); diff != "" {
+			t.Errorf("TimeoutFor(%d) mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		file string
+		data string
+	}{
+		"unknown toml key":     {".giwo.toml", "worktree_directory = \"x\"\n"},
+		"unknown yaml key":     {".giwo.yaml", "worktree_directory: x\n"},
+		"bad fetch policy":     {".giwo.toml", "fetch = \"sometimes\"\n"},
+		"bad fetch max age":    {".giwo.toml", "fetch_max_age = \"-5m\"\n"},
+		"malformed toml":       {".giwo.toml", "worktree_dir = \n"},
+		"negative jobs":        {".giwo.toml", "jobs = -1\n"},
+		"unknown placeholder":  {".giwo.toml", "worktree_path = \"../{name}-{branch}\"\n"},
+		"path without branch":  {".giwo.yaml", "worktree_path: ../{repo}-wt\n"},
+		"pattern outside repo": {".giwo.toml", "copy_files = [\"../secrets/.env\"]\n"},
+		"absolute pattern":     {".giwo.yaml", "symlink_files: [/etc/hosts]\n"},
+		"malformed pattern":    {".giwo.toml", "copy_exclude = [\"[cache\"]\n"},
+		"negative retention":   {".giwo.toml", "[trash]\nretention = \"-1h\"\n"},
+		"bad retention":        {".giwo.yaml", "trash:\n  retention: a week\n"},
+		"port block too high":  {".giwo.toml", "[ports]\nbase = 65530\n"},
+		"negative block size":  {".giwo.yaml", "ports:\n  block_size: -2\n"},
+		"bad env var name":     {".giwo.toml", "[env.vars]\n\"APP-PORT\" = \"1\"\n"},
+		"bad bootstrap mode":   {".giwo.toml", "[bootstrap]\nmode = \"symlink\"\n"},
+		"hook without run":     {".giwo.toml", "[[hooks.pre_remove]]\ntimeout = \"1m\"\n"},
+		"bad hook timeout":     {".giwo.yaml", "hooks:\n  post_switch:\n    - run: ls\n      timeout: soon\n"},
+		"wrong yaml type":      {".giwo.yaml", "protected_branches:\n  name: main\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			repoRoot := t.TempDir()
+			writeFile(t, filepath.Join(repoRoot, tt.file), tt.data)
+
+			_, err := Load(repoRoot)
+			if !errors.Is(err, giwoerrors.ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want %v", err, giwoerrors.ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestResolveWorktreeDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	for name, tt := range map[string]struct {
+		dir      string
+		expected string
+	}{
+		"default":       {"", "/repo/.worktree"},
+		"relative":      {"../worktrees", "/worktrees"},
+		"absolute":      {"/tmp/wt", "/tmp/wt"},
+		"home relative": {"~/wt", filepath.Join(home, "wt")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{WorktreeDir: tt.dir}
+			if diff := cmp.Diff(tt.expected, cfg.ResolveWorktreeDir("/repo")); diff != "" {
+				t.Errorf("ResolveWorktreeDir(%q) mismatch (-want +got):\n%s", tt.dir, diff)
+			}
+		})
+	}
+}
+
+func TestResolveWorktreePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	vars := PathVars{Root: "/src/giwo", Repo: "giwo", Owner: "knwoop", Branch: "feature/login"}
+	for name, tt := range map[string]struct {
+		cfg      Config
+		expected string
+	}{
+		"default":           {Config{}, "/src/giwo/.worktree/feature/login"},
+		"worktree dir":      {Config{WorktreeDir: "~/wt"}, filepath.Join(home, "wt", "feature", "login")},
+		"sibling with slug": {Config{WorktreePath: "../{repo}-{slug}"}, "/src/giwo-feature-login"},
+		"home with owner":   {Config{WorktreePath: "~/worktrees/{owner}/{repo}/{branch}"}, filepath.Join(home, "worktrees", "knwoop", "giwo", "feature", "login")},
+		"root":              {Config{WorktreePath: "{root}.wt/{slug}"}, "/src/giwo.wt/feature-login"},
+		"overrides dir":     {Config{WorktreeDir: "wt", WorktreePath: "/tmp/{slug}"}, "/tmp/feature-login"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, tt.cfg.ResolveWorktreePath(vars)); diff != "" {
+				t.Errorf("ResolveWorktreePath() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveWorktreeBase(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	vars := PathVars{Root: "/src/giwo", Repo: "giwo", Owner: "knwoop"}
+	for name, tt := range map[string]struct {
+		cfg      Config
+		expected string
+	}{
+		"default":           {Config{}, "/src/giwo/.worktree"},
+		"worktree dir":      {Config{WorktreeDir: "~/wt"}, filepath.Join(home, "wt")},
+		"sibling with slug": {Config{WorktreePath: "../{repo}-{slug}"}, "/src"},
+		"home with owner":   {Config{WorktreePath: "~/worktrees/{owner}/{repo}/{branch}"}, filepath.Join(home, "worktrees", "knwoop", "giwo")},
+		"root":              {Config{WorktreePath: "{root}.wt/{slug}"}, "/src/giwo.wt"},
+		"branch in middle":  {Config{WorktreePath: "/tmp/{slug}/{repo}"}, "/tmp"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, tt.cfg.ResolveWorktreeBase(vars)); diff != "" {
+				t.Errorf("ResolveWorktreeBase() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
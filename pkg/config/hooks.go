@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Hook is a shell command run at a worktree lifecycle event.
+type Hook struct {
+	// Run is the command line, executed with "sh -c".
+	Run string `toml:"run" yaml:"run" json:"run"`
+
+	// Timeout bounds the hook's run time. Zero falls back to Hooks.Timeout.
+	Timeout Duration `toml:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+}
+
+// Hooks groups the hooks for each lifecycle event.
+type Hooks struct {
+	// Timeout is the default timeout for hooks that do not set their own.
+	// Zero means no timeout.
+	Timeout Duration `toml:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+
+	PostCreate []Hook `toml:"post_create" yaml:"post_create" json:"post_create,omitempty"`
+	PreRemove  []Hook `toml:"pre_remove" yaml:"pre_remove" json:"pre_remove,omitempty"`
+	PostRemove []Hook `toml:"post_remove" yaml:"post_remove" json:"post_remove,omitempty"`
+	PostSwitch []Hook `toml:"post_switch" yaml:"post_switch" json:"post_switch,omitempty"`
+}
+
+// TimeoutFor returns the effective timeout of h.
+func (h *Hooks) TimeoutFor(hook Hook) time.Duration {
+	if hook.Timeout > 0 {
+		return time.Duration(hook.Timeout)
+	}
+	return time.Duration(h.Timeout)
+}
+
+// merge overrides each event list of h that other sets. The timeout is a
+// scalar and merged with the other scalars of the file.
+func (h *Hooks) merge(other *Hooks) {
+	if other.PostCreate != nil {
+		h.PostCreate = other.PostCreate
+	}
+	if other.PreRemove != nil {
+		h.PreRemove = other.PreRemove
+	}
+	if other.PostRemove != nil {
+		h.PostRemove = other.PostRemove
+	}
+	if other.PostSwitch != nil {
+		h.PostSwitch = other.PostSwitch
+	}
+}
+
+// validate reports hooks without a command or with a negative timeout.
+func (h *Hooks) validate() error {
+	if h.Timeout < 0 {
+		return fmt.Errorf("%w: hooks.timeout must not be negative", errors.ErrInvalidConfig)
+	}
+
+	for _, event := range []struct {
+		name  string
+		hooks []Hook
+	}{
+		{"post_create", h.PostCreate},
+		{"pre_remove", h.PreRemove},
+		{"post_remove", h.PostRemove},
+		{"post_switch", h.PostSwitch},
+	} {
+		for i, hook := range event.hooks {
+			if hook.Run == "" {
+				return fmt.Errorf("%w: hooks.%s[%d].run must not be empty", errors.ErrInvalidConfig, event.name, i)
+			}
+			if hook.Timeout < 0 {
+				return fmt.Errorf("%w: hooks.%s[%d].timeout must not be negative", errors.ErrInvalidConfig, event.name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "5m".
+// A whole number of days may be written as "30d".
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// ParseDuration parses s like time.ParseDuration and additionally accepts a
+// whole number of days such as "30d".
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
@@ -0,0 +1,206 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// BitbucketAPIBaseURL is the base URL for the Bitbucket Cloud API.
+const BitbucketAPIBaseURL = "https://api.bitbucket.org/2.0"
+
+// bitbucket implements Forge for Bitbucket Cloud.
+type bitbucket struct {
+	remote Remote
+	api    *apiClient
+	token  string
+}
+
+// bitbucketRepository is the subset of a Bitbucket repository response giwo uses.
+type bitbucketRepository struct {
+	FullName   string `json:"full_name"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+// bitbucketEndpoint is the source or destination of a Bitbucket pull request.
+type bitbucketEndpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit *struct {
+		Hash string `json:"hash"`
+	} `json:"commit,omitempty"`
+	Repository *bitbucketRepository `json:"repository,omitempty"`
+}
+
+// bitbucketPullRequest is the subset of a Bitbucket pull request response giwo uses.
+type bitbucketPullRequest struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	State string `json:"state"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	Source      bitbucketEndpoint `json:"source"`
+	Destination bitbucketEndpoint `json:"destination"`
+}
+
+// bitbucketPage is a page of a Bitbucket list response.
+type bitbucketPage struct {
+	Values []bitbucketPullRequest `json:"values"`
+}
+
+// newBitbucket creates a Bitbucket forge for remote.
+func newBitbucket(remote Remote, opts Options) *bitbucket {
+	b := &bitbucket{
+		remote: remote,
+		token:  opts.Token,
+	}
+	baseURL := BitbucketAPIBaseURL
+	if opts.APIURL != "" {
+		baseURL = opts.APIURL
+	}
+	b.api = &apiClient{
+		baseURL:    baseURL,
+		httpClient: opts.HTTPClient,
+		authorize: func(req *http.Request) {
+			if b.token != "" {
+				req.Header.Set("Authorization", "Bearer "+b.token)
+			}
+		},
+	}
+	return b
+}
+
+// Kind implements Forge.
+func (b *bitbucket) Kind() Kind {
+	return KindBitbucket
+}
+
+// Authenticated implements Forge.
+func (b *bitbucket) Authenticated() bool {
+	return b.token != ""
+}
+
+// DefaultBranch implements Forge.
+func (b *bitbucket) DefaultBranch(ctx context.Context) (string, error) {
+	var repo bitbucketRepository
+	if err := b.api.get(ctx, "/repositories/"+b.remote.Path(), &repo); err != nil {
+		return "", err
+	}
+	return repo.MainBranch.Name, nil
+}
+
+// PullRequest implements Forge.
+func (b *bitbucket) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr bitbucketPullRequest
+	if err := b.api.get(ctx, fmt.Sprintf("/repositories/%s/pullrequests/%d", b.remote.Path(), number), &pr); err != nil {
+		return nil, pullRequestNotFound(err, number)
+	}
+	return b.convert(&pr), nil
+}
+
+// OpenPullRequests implements Forge.
+func (b *bitbucket) OpenPullRequests(ctx context.Context) ([]*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "OPEN")
+	query.Set("sort", "-updated_on")
+	query.Set("pagelen", "50")
+
+	var page bitbucketPage
+	if err := b.api.get(ctx, fmt.Sprintf("/repositories/%s/pullrequests?%s", b.remote.Path(), query.Encode()), &page); err != nil {
+		return nil, err
+	}
+
+	converted := make([]*PullRequest, 0, len(page.Values))
+	for i := range page.Values {
+		converted = append(converted, b.convert(&page.Values[i]))
+	}
+	return converted, nil
+}
+
+// MergedPullRequest implements Forge.
+func (b *bitbucket) MergedPullRequest(ctx context.Context, branch string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "MERGED")
+	query.Set("q", fmt.Sprintf("source.branch.name=%q", branch))
+	query.Set("sort", "-updated_on")
+
+	var page bitbucketPage
+	if err := b.api.get(ctx, fmt.Sprintf("/repositories/%s/pullrequests?%s", b.remote.Path(), query.Encode()), &page); err != nil {
+		return nil, err
+	}
+
+	for i := range page.Values {
+		if pr := b.convert(&page.Values[i]); !pr.FromFork {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreatePullRequest implements Forge.
+func (b *bitbucket) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	var body struct {
+		Title       string            `json:"title"`
+		Description string            `json:"description,omitempty"`
+		Draft       bool              `json:"draft,omitempty"`
+		Source      bitbucketEndpoint `json:"source"`
+		Destination bitbucketEndpoint `json:"destination"`
+	}
+	body.Title = opts.Title
+	body.Description = opts.Body
+	body.Draft = opts.Draft
+	body.Source.Branch.Name = opts.Head
+	body.Destination.Branch.Name = opts.Base
+
+	var pr bitbucketPullRequest
+	if err := b.api.do(ctx, http.MethodPost, fmt.Sprintf("/repositories/%s/pullrequests", b.remote.Path()), body, &pr); err != nil {
+		return nil, err
+	}
+	return b.convert(&pr), nil
+}
+
+// PullRequestRef implements Forge.
+// Bitbucket does not publish pull request refs, so callers fetch the head
+// branch from HeadCloneURL instead.
+func (b *bitbucket) PullRequestRef(number int) string {
+	return ""
+}
+
+// convert converts a Bitbucket pull request.
+func (b *bitbucket) convert(pr *bitbucketPullRequest) *PullRequest {
+	state := StateOpen
+	switch pr.State {
+	case "MERGED":
+		state = StateMerged
+	case "DECLINED", "SUPERSEDED":
+		state = StateClosed
+	}
+
+	converted := &PullRequest{
+		Number:  pr.ID,
+		Title:   pr.Title,
+		State:   state,
+		URL:     pr.Links.HTML.Href,
+		HeadRef: pr.Source.Branch.Name,
+		BaseRef: pr.Destination.Branch.Name,
+	}
+	if pr.Source.Commit != nil {
+		converted.HeadSHA = pr.Source.Commit.Hash
+	}
+
+	headRepo := b.remote.Path()
+	if pr.Source.Repository != nil && pr.Source.Repository.FullName != "" {
+		headRepo = pr.Source.Repository.FullName
+	}
+	converted.FromFork = headRepo != b.remote.Path()
+	converted.HeadCloneURL = fmt.Sprintf("https://%s/%s.git", b.remote.Host, headRepo)
+
+	return converted
+}
@@ -0,0 +1,110 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func bitbucketHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repositories/workspace/repo":
+			fmt.Fprint(w, `{"full_name": "workspace/repo", "mainbranch": {"name": "develop"}}`)
+		case "GET /repositories/workspace/repo/pullrequests/12":
+			fmt.Fprint(w, `{
+				"id": 12, "title": "Feature", "state": "OPEN",
+				"links": {"html": {"href": "https://bitbucket.org/workspace/repo/pull-requests/12"}},
+				"source": {"branch": {"name": "feature"}, "commit": {"hash": "abc123"}, "repository": {"full_name": "bob/repo"}},
+				"destination": {"branch": {"name": "develop"}, "repository": {"full_name": "workspace/repo"}}
+			}`)
+		case "GET /repositories/workspace/repo/pullrequests":
+			if r.URL.Query().Get("state") == "OPEN" {
+				fmt.Fprint(w, `{"values": [{"id": 12, "state": "OPEN", "source": {"branch": {"name": "feature"}}}]}`)
+				return
+			}
+			if r.URL.Query().Get("q") != `source.branch.name="feature"` {
+				fmt.Fprint(w, `{"values": []}`)
+				return
+			}
+			fmt.Fprint(w, `{"values": [
+				{"id": 11, "state": "MERGED", "source": {"branch": {"name": "feature"}, "commit": {"hash": "def456"}, "repository": {"full_name": "workspace/repo"}}}
+			]}`)
+		case "POST /repositories/workspace/repo/pullrequests":
+			var body struct {
+				Title  string            `json:"title"`
+				Source bitbucketEndpoint `json:"source"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			fmt.Fprintf(w, `{"id": 13, "title": %q, "state": "OPEN", "source": {"branch": {"name": %q}}}`,
+				body.Title, body.Source.Branch.Name)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestBitbucket(t *testing.T) {
+	ctx := context.Background()
+	f := newTestForge(t, KindBitbucket, "git@bitbucket.org:workspace/repo.git", bitbucketHandler(t))
+
+	branch, err := f.DefaultBranch(ctx)
+	if err != nil {
+		t.Fatalf("DefaultBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("develop", branch); diff != "" {
+		t.Errorf("DefaultBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	pr, err := f.PullRequest(ctx, 12)
+	if err != nil {
+		t.Fatalf("PullRequest() unexpected error: %v", err)
+	}
+	expected := &PullRequest{
+		Number: 12, Title: "Feature", State: StateOpen, URL: "https://bitbucket.org/workspace/repo/pull-requests/12",
+		HeadRef: "feature", HeadSHA: "abc123", BaseRef: "develop",
+		HeadCloneURL: "https://bitbucket.org/bob/repo.git", FromFork: true,
+	}
+	if diff := cmp.Diff(expected, pr); diff != "" {
+		t.Errorf("PullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	merged, err := f.MergedPullRequest(ctx, "feature")
+	if err != nil {
+		t.Fatalf("MergedPullRequest() unexpected error: %v", err)
+	}
+	if merged == nil || merged.Number != 11 || merged.HeadSHA != "def456" {
+		t.Errorf("MergedPullRequest() = %+v, want pull request #11", merged)
+	}
+
+	open, err := f.OpenPullRequests(ctx)
+	if err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{12}, pullRequestNumbers(open)); diff != "" {
+		t.Errorf("OpenPullRequests() mismatch (-want +got):\n%s", diff)
+	}
+
+	created, err := f.CreatePullRequest(ctx, CreatePullRequestOptions{Title: "New", Head: "topic", Base: "develop"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() unexpected error: %v", err)
+	}
+	if created.Number != 13 || created.HeadRef != "topic" {
+		t.Errorf("CreatePullRequest() = %+v, want pull request #13 from topic", created)
+	}
+
+	if diff := cmp.Diff("", f.PullRequestRef(12)); diff != "" {
+		t.Errorf("PullRequestRef() mismatch (-want +got):\n%s", diff)
+	}
+}
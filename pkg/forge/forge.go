@@ -0,0 +1,194 @@
+// Package forge provides a common interface over Git hosting services such as
+// GitHub, GitLab, Gitea/Forgejo and Bitbucket.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// DefaultRequestTimeout is the default timeout for HTTP requests.
+const DefaultRequestTimeout = 10 * time.Second
+
+// Kind identifies a forge implementation.
+type Kind string
+
+// Forge kind constants.
+const (
+	KindGitHub    Kind = "github"
+	KindGitLab    Kind = "gitlab"
+	KindGitea     Kind = "gitea"
+	KindBitbucket Kind = "bitbucket"
+)
+
+// State is the state of a pull request.
+type State string
+
+// Pull request state constants.
+const (
+	StateOpen   State = "open"
+	StateClosed State = "closed"
+	StateMerged State = "merged"
+)
+
+// PullRequest is a pull request, or a merge request on GitLab.
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   State  `json:"state"`
+	URL     string `json:"url"`
+	HeadRef string `json:"head_ref"`
+	HeadSHA string `json:"head_sha"`
+	BaseRef string `json:"base_ref"`
+
+	// HeadCloneURL is the clone URL of the repository holding the head branch.
+	HeadCloneURL string `json:"head_clone_url,omitempty"`
+
+	// FromFork reports whether the head branch lives in another repository.
+	FromFork bool `json:"from_fork"`
+}
+
+// CreatePullRequestOptions holds the fields of a pull request to create.
+type CreatePullRequestOptions struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+	Draft bool
+}
+
+// Forge is a Git hosting service holding the repository.
+type Forge interface {
+	// Kind returns the forge type.
+	Kind() Kind
+
+	// Authenticated reports whether requests carry credentials.
+	Authenticated() bool
+
+	// DefaultBranch returns the default branch of the repository.
+	DefaultBranch(ctx context.Context) (string, error)
+
+	// PullRequest returns pull request number.
+	PullRequest(ctx context.Context, number int) (*PullRequest, error)
+
+	// OpenPullRequests returns the most recently updated open pull requests,
+	// up to one page of results.
+	OpenPullRequests(ctx context.Context) ([]*PullRequest, error)
+
+	// MergedPullRequest returns the most recently merged pull request whose
+	// head is branch in the repository itself, or nil if there is none.
+	MergedPullRequest(ctx context.Context, branch string) (*PullRequest, error)
+
+	// CreatePullRequest opens a pull request.
+	CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error)
+
+	// PullRequestRef returns the ref under which the forge publishes the head
+	// of pull request number, or an empty string if it does not publish one.
+	PullRequestRef(number int) string
+}
+
+// Options configures the forge returned by New.
+type Options struct {
+	// Kind selects the implementation. Empty detects it from the remote host.
+	Kind Kind
+
+	// APIURL overrides the API endpoint derived from the remote host.
+	APIURL string
+
+	// Token authenticates requests. Empty reads the token from the
+	// environment variables of the forge kind.
+	Token string
+
+	// HTTPClient sends requests. Nil uses a client with DefaultRequestTimeout.
+	HTTPClient *http.Client
+
+	// Git runs git in the repository for forges that fall back to local
+	// refs. Nil runs git from PATH in the current directory.
+	Git func(ctx context.Context, args ...string) (string, error)
+
+	// Remote is the name of the remote the repository was read from. Empty
+	// means origin.
+	Remote string
+}
+
+// tokenEnv lists the environment variables holding a token for each kind.
+var tokenEnv = map[Kind][]string{
+	KindGitHub:    {"GITHUB_TOKEN", "GH_TOKEN"},
+	KindGitLab:    {"GITLAB_TOKEN"},
+	KindGitea:     {"GITEA_TOKEN", "FORGEJO_TOKEN"},
+	KindBitbucket: {"BITBUCKET_TOKEN"},
+}
+
+// New returns the forge hosting the repository at remoteURL.
+func New(remoteURL string, opts Options) (Forge, error) {
+	remote, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := opts.Kind
+	if kind == "" {
+		var ok bool
+		if kind, ok = Detect(remote.Host); !ok {
+			return nil, fmt.Errorf("%w: cannot detect forge for host %s", errors.ErrUnsupportedForge, remote.Host)
+		}
+	}
+
+	if opts.Token == "" {
+		for _, name := range tokenEnv[kind] {
+			if opts.Token = os.Getenv(name); opts.Token != "" {
+				break
+			}
+		}
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: DefaultRequestTimeout}
+	}
+
+	switch kind {
+	case KindGitHub:
+		return newGitHub(remote, opts), nil
+	case KindGitLab:
+		return newGitLab(remote, opts), nil
+	case KindGitea:
+		return newGitea(remote, opts), nil
+	case KindBitbucket:
+		return newBitbucket(remote, opts), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnsupportedForge, kind)
+	}
+}
+
+// Detect returns the forge kind for a well-known or conventionally named host.
+func Detect(host string) (Kind, bool) {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return KindGitHub, true
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return KindGitLab, true
+	case host == "bitbucket.org":
+		return KindBitbucket, true
+	case host == "codeberg.org" || host == "gitea.com" ||
+		strings.Contains(host, "gitea") || strings.Contains(host, "forgejo"):
+		return KindGitea, true
+	default:
+		return "", false
+	}
+}
+
+// apiURL returns override if set, and the API endpoint at path on the
+// remote host otherwise.
+func apiURL(remote Remote, override, path string) string {
+	if override != "" {
+		return strings.TrimSuffix(override, "/")
+	}
+	return fmt.Sprintf("%s://%s%s", remote.Scheme, remote.Host, path)
+}
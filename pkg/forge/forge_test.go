@@ -0,0 +1,102 @@
+package forge
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestDetect(t *testing.T) {
+	for name, tt := range map[string]struct {
+		host     string
+		expected Kind
+		ok       bool
+	}{
+		"github.com":         {"github.com", KindGitHub, true},
+		"GitHub Enterprise":  {"github.example.com", KindGitHub, true},
+		"gitlab.com":         {"gitlab.com", KindGitLab, true},
+		"self-hosted GitLab": {"gitlab.internal.example.com", KindGitLab, true},
+		"bitbucket.org":      {"bitbucket.org", KindBitbucket, true},
+		"codeberg.org":       {"codeberg.org", KindGitea, true},
+		"self-hosted Gitea":  {"gitea.example.com", KindGitea, true},
+		"Forgejo":            {"forgejo.example.com", KindGitea, true},
+		"unknown host":       {"git.example.com", "", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			kind, ok := Detect(tt.host)
+			if diff := cmp.Diff(tt.expected, kind); diff != "" {
+				t.Errorf("Detect(%q) kind mismatch (-want +got):\n%s", tt.host, diff)
+			}
+			if diff := cmp.Diff(tt.ok, ok); diff != "" {
+				t.Errorf("Detect(%q) ok mismatch (-want +got):\n%s", tt.host, diff)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "secret")
+
+	for name, tt := range map[string]struct {
+		url      string
+		opts     Options
+		wantKind Kind
+		wantAuth bool
+		wantErr  error
+	}{
+		"detects GitLab with env token": {url: "git@gitlab.com:group/repo.git", wantKind: KindGitLab, wantAuth: true},
+		"explicit kind for custom host": {url: "git@git.example.com:team/app.git", opts: Options{Kind: KindGitea, Token: "t"}, wantKind: KindGitea, wantAuth: true},
+		"unknown host":                  {url: "git@git.example.com:team/app.git", wantErr: giwoerrors.ErrUnsupportedForge},
+		"unknown kind":                  {url: "git@github.com:a/b.git", opts: Options{Kind: "sourcehut"}, wantErr: giwoerrors.ErrUnsupportedForge},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(tt.url, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("New(%q) error = %v, want %v", tt.url, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(%q) unexpected error: %v", tt.url, err)
+			}
+
+			if diff := cmp.Diff(tt.wantKind, f.Kind()); diff != "" {
+				t.Errorf("Kind() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantAuth, f.Authenticated()); diff != "" {
+				t.Errorf("Authenticated() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// newTestForge starts server and returns a forge of kind talking to it.
+func newTestForge(t *testing.T, kind Kind, remoteURL string, handler http.HandlerFunc) Forge {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	f, err := New(remoteURL, Options{Kind: kind, APIURL: server.URL, Token: "test-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return f
+}
+
+// pullRequestNumbers returns the numbers of prs.
+func pullRequestNumbers(prs []*PullRequest) []int {
+	numbers := make([]int, 0, len(prs))
+	for _, pr := range prs {
+		numbers = append(numbers, pr.Number)
+	}
+	return numbers
+}
@@ -0,0 +1,175 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// giteaListLimit is the page size used when listing pull requests.
+const giteaListLimit = 50
+
+// gitea implements Forge for Gitea and Forgejo, including Codeberg.
+type gitea struct {
+	remote Remote
+	api    *apiClient
+	token  string
+}
+
+// giteaRepository is the subset of a Gitea repository response giwo uses.
+type giteaRepository struct {
+	FullName      string `json:"full_name"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// giteaBranch is the head or base of a Gitea pull request.
+type giteaBranch struct {
+	Ref  string           `json:"ref"`
+	SHA  string           `json:"sha"`
+	Repo *giteaRepository `json:"repo"`
+}
+
+// giteaPullRequest is the subset of a Gitea pull request response giwo uses.
+type giteaPullRequest struct {
+	Number  int         `json:"number"`
+	Title   string      `json:"title"`
+	State   string      `json:"state"`
+	Merged  bool        `json:"merged"`
+	HTMLURL string      `json:"html_url"`
+	Head    giteaBranch `json:"head"`
+	Base    giteaBranch `json:"base"`
+}
+
+// newGitea creates a Gitea forge for remote.
+func newGitea(remote Remote, opts Options) *gitea {
+	g := &gitea{
+		remote: remote,
+		token:  opts.Token,
+	}
+	g.api = &apiClient{
+		baseURL:    apiURL(remote, opts.APIURL, "/api/v1"),
+		httpClient: opts.HTTPClient,
+		authorize: func(req *http.Request) {
+			if g.token != "" {
+				req.Header.Set("Authorization", "token "+g.token)
+			}
+		},
+	}
+	return g
+}
+
+// Kind implements Forge.
+func (g *gitea) Kind() Kind {
+	return KindGitea
+}
+
+// Authenticated implements Forge.
+func (g *gitea) Authenticated() bool {
+	return g.token != ""
+}
+
+// DefaultBranch implements Forge.
+func (g *gitea) DefaultBranch(ctx context.Context) (string, error) {
+	var repo giteaRepository
+	if err := g.api.get(ctx, "/repos/"+g.remote.Path(), &repo); err != nil {
+		return "", err
+	}
+	return repo.DefaultBranch, nil
+}
+
+// PullRequest implements Forge.
+func (g *gitea) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr giteaPullRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", g.remote.Path(), number), &pr); err != nil {
+		return nil, pullRequestNotFound(err, number)
+	}
+	return pr.convert(), nil
+}
+
+// OpenPullRequests implements Forge.
+func (g *gitea) OpenPullRequests(ctx context.Context) ([]*PullRequest, error) {
+	var prs []giteaPullRequest
+	path := fmt.Sprintf("/repos/%s/pulls?state=open&sort=recentupdate&limit=%d", g.remote.Path(), giteaListLimit)
+	if err := g.api.get(ctx, path, &prs); err != nil {
+		return nil, err
+	}
+
+	converted := make([]*PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		converted = append(converted, pr.convert())
+	}
+	return converted, nil
+}
+
+// MergedPullRequest implements Forge.
+// Gitea cannot filter pull requests by head branch, so the most recently
+// updated closed pull requests are scanned instead.
+func (g *gitea) MergedPullRequest(ctx context.Context, branch string) (*PullRequest, error) {
+	var prs []giteaPullRequest
+	path := fmt.Sprintf("/repos/%s/pulls?state=closed&sort=recentupdate&limit=%d", g.remote.Path(), giteaListLimit)
+	if err := g.api.get(ctx, path, &prs); err != nil {
+		return nil, err
+	}
+
+	for _, pr := range prs {
+		converted := pr.convert()
+		if pr.Merged && pr.Head.Ref == branch && !converted.FromFork {
+			return converted, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreatePullRequest implements Forge.
+func (g *gitea) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		title = "WIP: " + title
+	}
+
+	body := map[string]string{
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"title": title,
+		"body":  opts.Body,
+	}
+
+	var pr giteaPullRequest
+	if err := g.api.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.remote.Path()), body, &pr); err != nil {
+		return nil, err
+	}
+	return pr.convert(), nil
+}
+
+// PullRequestRef implements Forge.
+func (g *gitea) PullRequestRef(number int) string {
+	return fmt.Sprintf("refs/pull/%d/head", number)
+}
+
+// convert converts a Gitea pull request.
+func (pr *giteaPullRequest) convert() *PullRequest {
+	state := StateOpen
+	switch {
+	case pr.Merged:
+		state = StateMerged
+	case pr.State == "closed":
+		state = StateClosed
+	}
+
+	converted := &PullRequest{
+		Number:   pr.Number,
+		Title:    pr.Title,
+		State:    state,
+		URL:      pr.HTMLURL,
+		HeadRef:  pr.Head.Ref,
+		HeadSHA:  pr.Head.SHA,
+		BaseRef:  pr.Base.Ref,
+		FromFork: true,
+	}
+	if pr.Head.Repo != nil {
+		converted.HeadCloneURL = pr.Head.Repo.CloneURL
+		converted.FromFork = pr.Base.Repo == nil || pr.Head.Repo.FullName != pr.Base.Repo.FullName
+	}
+	return converted
+}
@@ -0,0 +1,106 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func giteaHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/team/app":
+			fmt.Fprint(w, `{"full_name": "team/app", "default_branch": "main"}`)
+		case "GET /repos/team/app/pulls/3":
+			fmt.Fprint(w, `{
+				"number": 3, "title": "Docs", "state": "open", "merged": false,
+				"head": {"ref": "docs", "sha": "abc", "repo": {"full_name": "alice/app", "clone_url": "https://gitea.example.com/alice/app.git"}},
+				"base": {"ref": "main", "repo": {"full_name": "team/app"}}
+			}`)
+		case "GET /repos/team/app/pulls":
+			if r.URL.Query().Get("state") == "open" {
+				fmt.Fprint(w, `[{"number": 3, "state": "open", "head": {"ref": "docs"}}]`)
+				return
+			}
+			fmt.Fprint(w, `[
+				{"number": 5, "state": "closed", "merged": false, "head": {"ref": "docs", "repo": {"full_name": "team/app"}}, "base": {"repo": {"full_name": "team/app"}}},
+				{"number": 4, "state": "closed", "merged": true, "head": {"ref": "docs", "sha": "def", "repo": {"full_name": "team/app"}}, "base": {"repo": {"full_name": "team/app"}}}
+			]`)
+		case "POST /repos/team/app/pulls":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			fmt.Fprintf(w, `{"number": 6, "title": %q, "state": "open", "head": {"ref": %q}, "base": {"ref": %q}}`,
+				body["title"], body["head"], body["base"])
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestGitea(t *testing.T) {
+	ctx := context.Background()
+	f := newTestForge(t, KindGitea, "https://gitea.example.com/team/app.git", giteaHandler(t))
+
+	branch, err := f.DefaultBranch(ctx)
+	if err != nil {
+		t.Fatalf("DefaultBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("main", branch); diff != "" {
+		t.Errorf("DefaultBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	pr, err := f.PullRequest(ctx, 3)
+	if err != nil {
+		t.Fatalf("PullRequest() unexpected error: %v", err)
+	}
+	expected := &PullRequest{
+		Number: 3, Title: "Docs", State: StateOpen, HeadRef: "docs", HeadSHA: "abc", BaseRef: "main",
+		HeadCloneURL: "https://gitea.example.com/alice/app.git", FromFork: true,
+	}
+	if diff := cmp.Diff(expected, pr); diff != "" {
+		t.Errorf("PullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	merged, err := f.MergedPullRequest(ctx, "docs")
+	if err != nil {
+		t.Fatalf("MergedPullRequest() unexpected error: %v", err)
+	}
+	if merged == nil || merged.Number != 4 || merged.HeadSHA != "def" {
+		t.Errorf("MergedPullRequest() = %+v, want pull request #4", merged)
+	}
+
+	if merged, err := f.MergedPullRequest(ctx, "other"); err != nil || merged != nil {
+		t.Errorf("MergedPullRequest(other) = %+v, %v, want nil, nil", merged, err)
+	}
+
+	open, err := f.OpenPullRequests(ctx)
+	if err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{3}, pullRequestNumbers(open)); diff != "" {
+		t.Errorf("OpenPullRequests() mismatch (-want +got):\n%s", diff)
+	}
+
+	created, err := f.CreatePullRequest(ctx, CreatePullRequestOptions{Title: "New", Head: "feature", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&PullRequest{Number: 6, Title: "New", State: StateOpen, HeadRef: "feature", BaseRef: "main", FromFork: true}, created); diff != "" {
+		t.Errorf("CreatePullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff("refs/pull/3/head", f.PullRequestRef(3)); diff != "" {
+		t.Errorf("PullRequestRef() mismatch (-want +got):\n%s", diff)
+	}
+}
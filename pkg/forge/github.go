@@ -0,0 +1,225 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// gitHubAPIURL is the API endpoint of github.com.
+const gitHubAPIURL = "https://api.github.com"
+
+// gitHub implements Forge for github.com and GitHub Enterprise.
+type gitHub struct {
+	remote Remote
+	api    *apiClient
+	token  string
+
+	// git and remoteName locate the remote-tracking branches the default
+	// branch is guessed from when the API cannot be used.
+	git        func(ctx context.Context, args ...string) (string, error)
+	remoteName string
+}
+
+// gitHubRepository is the subset of a GitHub repository response giwo uses.
+type gitHubRepository struct {
+	FullName      string `json:"full_name"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// gitHubRef is the head or base of a GitHub pull request.
+type gitHubRef struct {
+	Ref  string            `json:"ref"`
+	SHA  string            `json:"sha"`
+	Repo *gitHubRepository `json:"repo"`
+}
+
+// gitHubPullRequest is the subset of a GitHub pull request response giwo uses.
+type gitHubPullRequest struct {
+	Number   int        `json:"number"`
+	Title    string     `json:"title"`
+	State    string     `json:"state"`
+	Merged   bool       `json:"merged"`
+	MergedAt *time.Time `json:"merged_at"`
+	HTMLURL  string     `json:"html_url"`
+	Head     gitHubRef  `json:"head"`
+	Base     gitHubRef  `json:"base"`
+}
+
+// newGitHub creates a GitHub forge for remote.
+func newGitHub(remote Remote, opts Options) *gitHub {
+	baseURL := apiURL(remote, opts.APIURL, "/api/v3")
+	if remote.Host == "github.com" && opts.APIURL == "" {
+		baseURL = gitHubAPIURL
+	}
+
+	g := &gitHub{
+		remote:     remote,
+		token:      opts.Token,
+		git:        opts.Git,
+		remoteName: opts.Remote,
+	}
+	if g.git == nil {
+		g.git = execGit
+	}
+	if g.remoteName == "" {
+		g.remoteName = "origin"
+	}
+	g.api = &apiClient{
+		baseURL:    baseURL,
+		httpClient: opts.HTTPClient,
+		authorize: func(req *http.Request) {
+			req.Header.Set("Accept", "application/vnd.github.v3+json")
+			if g.token != "" {
+				req.Header.Set("Authorization", "token "+g.token)
+			}
+		},
+	}
+	return g
+}
+
+// Kind implements Forge.
+func (g *gitHub) Kind() Kind {
+	return KindGitHub
+}
+
+// Authenticated implements Forge.
+func (g *gitHub) Authenticated() bool {
+	return g.token != ""
+}
+
+// DefaultBranch implements Forge. Without a token, or when the API fails, it
+// falls back to the remote-tracking branches of the local repository.
+func (g *gitHub) DefaultBranch(ctx context.Context) (string, error) {
+	if g.token == "" {
+		return g.localDefaultBranch(ctx), nil
+	}
+
+	var repository gitHubRepository
+	if err := g.api.get(ctx, "/repos/"+g.remote.Path(), &repository); err != nil {
+		return g.localDefaultBranch(ctx), nil
+	}
+	return repository.DefaultBranch, nil
+}
+
+// localDefaultBranch returns the first of main, master and develop the remote
+// has a remote-tracking branch for, and main if it has none.
+func (g *gitHub) localDefaultBranch(ctx context.Context) string {
+	for _, branch := range []string{"main", "master", "develop"} {
+		ref := fmt.Sprintf("refs/remotes/%s/%s", g.remoteName, branch)
+		if _, err := g.git(ctx, "rev-parse", "--verify", "--quiet", ref); err == nil {
+			return branch
+		}
+	}
+	return "main"
+}
+
+// PullRequest implements Forge. Public repositories can be queried without
+// a token.
+func (g *gitHub) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr gitHubPullRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", g.remote.Path(), number), &pr); err != nil {
+		return nil, pullRequestNotFound(err, number)
+	}
+	return pr.convert(), nil
+}
+
+// OpenPullRequests implements Forge.
+func (g *gitHub) OpenPullRequests(ctx context.Context) ([]*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("sort", "updated")
+	query.Set("direction", "desc")
+	query.Set("per_page", "100")
+
+	var prs []gitHubPullRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/repos/%s/pulls?%s", g.remote.Path(), query.Encode()), &prs); err != nil {
+		return nil, err
+	}
+
+	converted := make([]*PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		converted = append(converted, pr.convert())
+	}
+	return converted, nil
+}
+
+// MergedPullRequest implements Forge.
+func (g *gitHub) MergedPullRequest(ctx context.Context, branch string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "closed")
+	query.Set("head", fmt.Sprintf("%s:%s", g.remote.Owner, branch))
+	query.Set("sort", "updated")
+	query.Set("direction", "desc")
+
+	var prs []gitHubPullRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/repos/%s/pulls?%s", g.remote.Path(), query.Encode()), &prs); err != nil {
+		return nil, err
+	}
+
+	for _, pr := range prs {
+		if pr.MergedAt != nil {
+			return pr.convert(), nil
+		}
+	}
+	return nil, nil
+}
+
+// CreatePullRequest implements Forge.
+func (g *gitHub) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	body := map[string]any{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"draft": opts.Draft,
+	}
+
+	var pr gitHubPullRequest
+	if err := g.api.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.remote.Path()), body, &pr); err != nil {
+		return nil, err
+	}
+	return pr.convert(), nil
+}
+
+// PullRequestRef implements Forge.
+func (g *gitHub) PullRequestRef(number int) string {
+	return fmt.Sprintf("refs/pull/%d/head", number)
+}
+
+// convert converts a GitHub pull request.
+func (pr *gitHubPullRequest) convert() *PullRequest {
+	state := StateOpen
+	switch {
+	case pr.Merged || pr.MergedAt != nil:
+		state = StateMerged
+	case pr.State == "closed":
+		state = StateClosed
+	}
+
+	converted := &PullRequest{
+		Number:   pr.Number,
+		Title:    pr.Title,
+		State:    state,
+		URL:      pr.HTMLURL,
+		HeadRef:  pr.Head.Ref,
+		HeadSHA:  pr.Head.SHA,
+		BaseRef:  pr.Base.Ref,
+		FromFork: pr.Head.Repo == nil || pr.Base.Repo == nil || pr.Head.Repo.FullName != pr.Base.Repo.FullName,
+	}
+	if pr.Head.Repo != nil {
+		converted.HeadCloneURL = pr.Head.Repo.CloneURL
+	}
+	return converted
+}
+
+// execGit runs git in the current directory.
+func execGit(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	return strings.TrimSpace(string(output)), err
+}
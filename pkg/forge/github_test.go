@@ -0,0 +1,157 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestGitHub(t *testing.T) {
+	ctx := context.Background()
+	f := newTestForge(t, KindGitHub, "git@github.example.com:knwoop/giwo.git", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/knwoop/giwo":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case "GET /repos/knwoop/giwo/pulls/5":
+			fmt.Fprint(w, `{
+				"number": 5, "title": "Feature", "state": "closed", "merged": true,
+				"head": {"ref": "feature", "sha": "abc", "repo": {"full_name": "knwoop/giwo", "clone_url": "https://github.example.com/knwoop/giwo.git"}},
+				"base": {"ref": "main", "repo": {"full_name": "knwoop/giwo"}}
+			}`)
+		case "GET /repos/knwoop/giwo/pulls/1234":
+			fmt.Fprint(w, `{
+				"number": 1234, "title": "Add review command", "state": "open",
+				"head": {"ref": "review", "repo": {"full_name": "alice/giwo"}},
+				"base": {"ref": "main", "repo": {"full_name": "knwoop/giwo"}}
+			}`)
+		case "GET /repos/knwoop/giwo/pulls":
+			switch r.URL.Query().Get("state") + " " + r.URL.Query().Get("head") {
+			case "open ":
+				fmt.Fprint(w, `[{"number": 8, "title": "Open", "state": "open", "head": {"ref": "open"}, "base": {"ref": "main"}}]`)
+			case "closed knwoop:squashed":
+				fmt.Fprint(w, `[
+					{"number": 12, "merged_at": null, "head": {"sha": "aaa"}},
+					{"number": 10, "merged_at": "2025-01-02T03:04:05Z", "head": {"sha": "bbb"}}
+				]`)
+			case "closed knwoop:closed":
+				fmt.Fprint(w, `[{"number": 11, "merged_at": null}]`)
+			default:
+				fmt.Fprint(w, `[]`)
+			}
+		case "POST /repos/knwoop/giwo/pulls":
+			fmt.Fprint(w, `{"number": 6, "title": "New", "state": "open", "head": {"ref": "topic"}, "base": {"ref": "main"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	branch, err := f.DefaultBranch(ctx)
+	if err != nil {
+		t.Fatalf("DefaultBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("main", branch); diff != "" {
+		t.Errorf("DefaultBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	pr, err := f.PullRequest(ctx, 5)
+	if err != nil {
+		t.Fatalf("PullRequest() unexpected error: %v", err)
+	}
+	expected := &PullRequest{
+		Number: 5, Title: "Feature", State: StateMerged, HeadRef: "feature", HeadSHA: "abc", BaseRef: "main",
+		HeadCloneURL: "https://github.example.com/knwoop/giwo.git",
+	}
+	if diff := cmp.Diff(expected, pr); diff != "" {
+		t.Errorf("PullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	fork, err := f.PullRequest(ctx, 1234)
+	if err != nil {
+		t.Fatalf("PullRequest() of a fork unexpected error: %v", err)
+	}
+	if !fork.FromFork || fork.HeadRef != "review" {
+		t.Errorf("PullRequest() = %+v, want pull request from fork branch review", fork)
+	}
+	if _, err := f.PullRequest(ctx, 1); !errors.Is(err, giwoerrors.ErrPullRequestNotFound) {
+		t.Errorf("PullRequest() of a missing pull request error = %v, want %v", err, giwoerrors.ErrPullRequestNotFound)
+	}
+
+	for branch, want := range map[string][]int{
+		"squashed": {10},
+		"closed":   {},
+		"unknown":  {},
+	} {
+		pr, err := f.MergedPullRequest(ctx, branch)
+		if err != nil {
+			t.Fatalf("MergedPullRequest(%s) unexpected error: %v", branch, err)
+		}
+		got := []int{}
+		if pr != nil {
+			got = append(got, pr.Number)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("MergedPullRequest(%s) mismatch (-want +got):\n%s", branch, diff)
+		}
+	}
+
+	open, err := f.OpenPullRequests(ctx)
+	if err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{8}, pullRequestNumbers(open)); diff != "" {
+		t.Errorf("OpenPullRequests() mismatch (-want +got):\n%s", diff)
+	}
+
+	created, err := f.CreatePullRequest(ctx, CreatePullRequestOptions{Title: "New", Head: "topic", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() unexpected error: %v", err)
+	}
+	if created.Number != 6 || created.HeadRef != "topic" {
+		t.Errorf("CreatePullRequest() = %+v, want pull request #6 from topic", created)
+	}
+
+	if diff := cmp.Diff("refs/pull/5/head", f.PullRequestRef(5)); diff != "" {
+		t.Errorf("PullRequestRef() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitHubLocalDefaultBranch(t *testing.T) {
+	var calls []string
+	git := func(ctx context.Context, args ...string) (string, error) {
+		line := strings.Join(args, " ")
+		calls = append(calls, line)
+		if line == "rev-parse --verify --quiet refs/remotes/upstream/master" {
+			return "1111111111111111111111111111111111111111", nil
+		}
+		return "", errors.New("exit status 1")
+	}
+
+	f := newGitHub(Remote{Scheme: "https", Host: "github.com", Owner: "knwoop", Name: "giwo"}, Options{Git: git, Remote: "upstream"})
+	branch, err := f.DefaultBranch(context.Background())
+	if err != nil {
+		t.Fatalf("DefaultBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("master", branch); diff != "" {
+		t.Errorf("DefaultBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	expected := []string{
+		"rev-parse --verify --quiet refs/remotes/upstream/main",
+		"rev-parse --verify --quiet refs/remotes/upstream/master",
+	}
+	if diff := cmp.Diff(expected, calls); diff != "" {
+		t.Errorf("git calls mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,168 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// gitLab implements Forge for gitlab.com and self-hosted GitLab.
+type gitLab struct {
+	project string
+	api     *apiClient
+	token   string
+}
+
+// gitLabProject is the subset of a GitLab project response giwo uses.
+type gitLabProject struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+// gitLabMergeRequest is the subset of a GitLab merge request response giwo uses.
+type gitLabMergeRequest struct {
+	IID             int    `json:"iid"`
+	Title           string `json:"title"`
+	State           string `json:"state"`
+	WebURL          string `json:"web_url"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SHA             string `json:"sha"`
+	SourceProjectID int    `json:"source_project_id"`
+	TargetProjectID int    `json:"target_project_id"`
+}
+
+// newGitLab creates a GitLab forge for remote.
+func newGitLab(remote Remote, opts Options) *gitLab {
+	g := &gitLab{
+		project: url.PathEscape(remote.Path()),
+		token:   opts.Token,
+	}
+	g.api = &apiClient{
+		baseURL:    apiURL(remote, opts.APIURL, "/api/v4"),
+		httpClient: opts.HTTPClient,
+		authorize: func(req *http.Request) {
+			if g.token != "" {
+				req.Header.Set("PRIVATE-TOKEN", g.token)
+			}
+		},
+	}
+	return g
+}
+
+// Kind implements Forge.
+func (g *gitLab) Kind() Kind {
+	return KindGitLab
+}
+
+// Authenticated implements Forge.
+func (g *gitLab) Authenticated() bool {
+	return g.token != ""
+}
+
+// DefaultBranch implements Forge.
+func (g *gitLab) DefaultBranch(ctx context.Context) (string, error) {
+	var project gitLabProject
+	if err := g.api.get(ctx, "/projects/"+g.project, &project); err != nil {
+		return "", err
+	}
+	return project.DefaultBranch, nil
+}
+
+// PullRequest implements Forge.
+func (g *gitLab) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var mr gitLabMergeRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, number), &mr); err != nil {
+		return nil, pullRequestNotFound(err, number)
+	}
+	return mr.convert(), nil
+}
+
+// OpenPullRequests implements Forge.
+func (g *gitLab) OpenPullRequests(ctx context.Context) ([]*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("order_by", "updated_at")
+	query.Set("sort", "desc")
+	query.Set("per_page", "100")
+
+	var mrs []gitLabMergeRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/projects/%s/merge_requests?%s", g.project, query.Encode()), &mrs); err != nil {
+		return nil, err
+	}
+
+	converted := make([]*PullRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		converted = append(converted, mr.convert())
+	}
+	return converted, nil
+}
+
+// MergedPullRequest implements Forge.
+func (g *gitLab) MergedPullRequest(ctx context.Context, branch string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "merged")
+	query.Set("source_branch", branch)
+	query.Set("order_by", "updated_at")
+	query.Set("sort", "desc")
+
+	var mrs []gitLabMergeRequest
+	if err := g.api.get(ctx, fmt.Sprintf("/projects/%s/merge_requests?%s", g.project, query.Encode()), &mrs); err != nil {
+		return nil, err
+	}
+
+	for _, mr := range mrs {
+		if mr.SourceProjectID == mr.TargetProjectID {
+			return mr.convert(), nil
+		}
+	}
+	return nil, nil
+}
+
+// CreatePullRequest implements Forge.
+func (g *gitLab) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		title = "Draft: " + title
+	}
+
+	body := map[string]string{
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+		"title":         title,
+		"description":   opts.Body,
+	}
+
+	var mr gitLabMergeRequest
+	if err := g.api.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", g.project), body, &mr); err != nil {
+		return nil, err
+	}
+	return mr.convert(), nil
+}
+
+// PullRequestRef implements Forge.
+func (g *gitLab) PullRequestRef(number int) string {
+	return fmt.Sprintf("refs/merge-requests/%d/head", number)
+}
+
+// convert converts a GitLab merge request.
+func (mr *gitLabMergeRequest) convert() *PullRequest {
+	state := StateOpen
+	switch mr.State {
+	case "merged":
+		state = StateMerged
+	case "closed", "locked":
+		state = StateClosed
+	}
+
+	return &PullRequest{
+		Number:   mr.IID,
+		Title:    mr.Title,
+		State:    state,
+		URL:      mr.WebURL,
+		HeadRef:  mr.SourceBranch,
+		HeadSHA:  mr.SHA,
+		BaseRef:  mr.TargetBranch,
+		FromFork: mr.SourceProjectID != mr.TargetProjectID,
+	}
+}
@@ -0,0 +1,106 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func gitLabHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch path := r.Method + " " + r.URL.EscapedPath(); path {
+		case "GET /projects/group%2Fsub%2Frepo":
+			fmt.Fprint(w, `{"default_branch": "trunk"}`)
+		case "GET /projects/group%2Fsub%2Frepo/merge_requests/7":
+			fmt.Fprint(w, `{"iid": 7, "title": "Fix", "state": "opened", "source_branch": "fix", "target_branch": "trunk", "sha": "abc", "source_project_id": 2, "target_project_id": 1}`)
+		case "GET /projects/group%2Fsub%2Frepo/merge_requests":
+			if r.URL.Query().Get("state") == "opened" {
+				fmt.Fprint(w, `[{"iid": 7, "state": "opened", "source_branch": "fix"}, {"iid": 5, "state": "opened", "source_branch": "docs"}]`)
+				return
+			}
+			if r.URL.Query().Get("state") != "merged" || r.URL.Query().Get("source_branch") != "fix" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[
+				{"iid": 9, "state": "merged", "source_branch": "fix", "sha": "fork", "source_project_id": 2, "target_project_id": 1},
+				{"iid": 8, "state": "merged", "source_branch": "fix", "sha": "def", "source_project_id": 1, "target_project_id": 1}
+			]`)
+		case "POST /projects/group%2Fsub%2Frepo/merge_requests":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			fmt.Fprintf(w, `{"iid": 10, "title": %q, "state": "opened", "source_branch": %q, "target_branch": %q, "source_project_id": 1, "target_project_id": 1}`,
+				body["title"], body["source_branch"], body["target_branch"])
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	ctx := context.Background()
+	f := newTestForge(t, KindGitLab, "git@gitlab.example.com:group/sub/repo.git", gitLabHandler(t))
+
+	branch, err := f.DefaultBranch(ctx)
+	if err != nil {
+		t.Fatalf("DefaultBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("trunk", branch); diff != "" {
+		t.Errorf("DefaultBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	pr, err := f.PullRequest(ctx, 7)
+	if err != nil {
+		t.Fatalf("PullRequest() unexpected error: %v", err)
+	}
+	expected := &PullRequest{Number: 7, Title: "Fix", State: StateOpen, HeadRef: "fix", HeadSHA: "abc", BaseRef: "trunk", FromFork: true}
+	if diff := cmp.Diff(expected, pr); diff != "" {
+		t.Errorf("PullRequest() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := f.PullRequest(ctx, 404); !errors.Is(err, giwoerrors.ErrPullRequestNotFound) {
+		t.Errorf("PullRequest(404) error = %v, want %v", err, giwoerrors.ErrPullRequestNotFound)
+	}
+
+	merged, err := f.MergedPullRequest(ctx, "fix")
+	if err != nil {
+		t.Fatalf("MergedPullRequest() unexpected error: %v", err)
+	}
+	if merged == nil || merged.Number != 8 || merged.State != StateMerged {
+		t.Errorf("MergedPullRequest() = %+v, want merge request !8 from the project itself", merged)
+	}
+
+	open, err := f.OpenPullRequests(ctx)
+	if err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{7, 5}, pullRequestNumbers(open)); diff != "" {
+		t.Errorf("OpenPullRequests() mismatch (-want +got):\n%s", diff)
+	}
+
+	created, err := f.CreatePullRequest(ctx, CreatePullRequestOptions{Title: "New", Head: "feature", Base: "trunk", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("Draft: New", created.Title); diff != "" {
+		t.Errorf("CreatePullRequest() title mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff("refs/merge-requests/7/head", f.PullRequestRef(7)); diff != "" {
+		t.Errorf("PullRequestRef() mismatch (-want +got):\n%s", diff)
+	}
+}
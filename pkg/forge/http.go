@@ -0,0 +1,83 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// errNotFound is returned by apiClient.do when the API responds with 404.
+var errNotFound = fmt.Errorf("%w: not found", errors.ErrForgeAPIUnavailable)
+
+// apiClient sends JSON requests to a forge REST API.
+type apiClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// authorize adds credentials to a request.
+	authorize func(req *http.Request)
+}
+
+// get performs a GET request and decodes the JSON response into v.
+func (c *apiClient) get(ctx context.Context, path string, v any) error {
+	return c.do(ctx, http.MethodGet, path, nil, v)
+}
+
+// do performs an API request with an optional JSON body and decodes the JSON
+// response into v.
+func (c *apiClient) do(ctx context.Context, method, path string, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "giwo-cli")
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrForgeAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("%w: %s", errors.ErrForgeAPIUnavailable, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// pullRequestNotFound maps errNotFound to ErrPullRequestNotFound.
+func pullRequestNotFound(err error, number int) error {
+	if err == errNotFound {
+		return fmt.Errorf("%w: #%d", errors.ErrPullRequestNotFound, number)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Remote identifies a repository on a forge host.
+type Remote struct {
+	// Scheme is the scheme used to reach the host's web and API endpoints.
+	Scheme string
+
+	// Host is the host name, without port for SSH remotes.
+	Host string
+
+	// Owner is the user, organization or group. On GitLab it may contain
+	// subgroups separated by slashes.
+	Owner string
+
+	// Name is the repository name without the .git suffix.
+	Name string
+}
+
+// Path returns the repository path as "owner/name".
+func (r Remote) Path() string {
+	return r.Owner + "/" + r.Name
+}
+
+// scpLikeRegex matches SSH remotes such as git@github.com:owner/repo.git.
+var scpLikeRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemoteURL parses an SSH, scp-like or HTTP(S) Git remote URL.
+func ParseRemoteURL(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+
+	var host, path string
+	scheme := "https"
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+		if u.Scheme == "http" {
+			scheme = "http"
+		}
+		if u.Scheme == "http" || u.Scheme == "https" {
+			host = u.Host
+		} else {
+			host = u.Hostname()
+		}
+		path = u.Path
+	} else if matches := scpLikeRegex.FindStringSubmatch(raw); matches != nil {
+		host, path = matches[1], matches[2]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	idx := strings.LastIndex(path, "/")
+	if host == "" || idx <= 0 || idx == len(path)-1 {
+		return Remote{}, fmt.Errorf("invalid remote URL %q: expected host/owner/repo", raw)
+	}
+
+	return Remote{
+		Scheme: scheme,
+		Host:   host,
+		Owner:  path[:idx],
+		Name:   path[idx+1:],
+	}, nil
+}
@@ -0,0 +1,47 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	for name, tt := range map[string]struct {
+		url      string
+		expected Remote
+		wantErr  bool
+	}{
+		"GitHub SSH":           {url: "git@github.com:knwoop/giwo.git", expected: Remote{"https", "github.com", "knwoop", "giwo"}},
+		"GitHub HTTPS":         {url: "https://github.com/knwoop/giwo", expected: Remote{"https", "github.com", "knwoop", "giwo"}},
+		"GitLab subgroup":      {url: "git@gitlab.example.com:group/sub/repo.git", expected: Remote{"https", "gitlab.example.com", "group/sub", "repo"}},
+		"SSH URL with port":    {url: "ssh://git@gitea.example.com:2222/team/app.git", expected: Remote{"https", "gitea.example.com", "team", "app"}},
+		"HTTP with port":       {url: "http://git.internal:3000/team/app.git", expected: Remote{"http", "git.internal:3000", "team", "app"}},
+		"HTTPS with user":      {url: "https://user@bitbucket.org/workspace/repo.git", expected: Remote{"https", "bitbucket.org", "workspace", "repo"}},
+		"Trailing slash":       {url: "https://codeberg.org/forgejo/forgejo/", expected: Remote{"https", "codeberg.org", "forgejo", "forgejo"}},
+		"Local path":           {url: "/srv/git/repo.git", wantErr: true},
+		"Missing owner":        {url: "https://github.com/giwo", wantErr: true},
+		"Empty URL":            {url: "", wantErr: true},
+		"Not a URL":            {url: "not-a-remote-url", wantErr: true},
+		"SSH alias with owner": {url: "work:knwoop/giwo.git", expected: Remote{"https", "work", "knwoop", "giwo"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			remote, err := ParseRemoteURL(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRemoteURL(%q) expected error but got %+v", tt.url, remote)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRemoteURL(%q) unexpected error: %v", tt.url, err)
+			}
+
+			if diff := cmp.Diff(tt.expected, remote); diff != "" {
+				t.Errorf("ParseRemoteURL(%q) mismatch (-want +got):\n%s", tt.url, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// trashVersion is the version of the trash file format.
+const trashVersion = 1
+
+// archiveRefPrefix is the namespace of the refs that keep archives reachable.
+const archiveRefPrefix = "refs/giwo/archive/"
+
+// Archive is a snapshot of a worktree taken right before it was removed.
+type Archive struct {
+	// Branch is the branch the worktree had checked out.
+	Branch string `json:"branch"`
+
+	// Ref is the hidden ref that keeps Commit from being garbage collected.
+	Ref string `json:"ref"`
+
+	// Head is the commit the branch pointed to.
+	Head string `json:"head"`
+
+	// Commit is a commit on top of Head whose tree is the content of the
+	// worktree, including uncommitted changes and untracked files.
+	Commit string `json:"commit"`
+
+	// Dirty reports whether Commit differs from Head.
+	Dirty bool `json:"dirty"`
+
+	// Detached reports whether the worktree had no branch. Branch is then
+	// only the name of the worktree.
+	Detached bool `json:"detached,omitempty"`
+
+	// Path is where the worktree was.
+	Path string `json:"path"`
+
+	// ArchivedAt is when the worktree was archived.
+	ArchivedAt time.Time `json:"archived_at"`
+
+	// Metadata is what giwo remembered about the worktree.
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Age returns how long ago the archive was taken, such as "3d ago".
+func (a *Archive) Age() string {
+	return formatTimeAgo(a.ArchivedAt)
+}
+
+// ExpiresAt returns when the archive is purged given the retention period.
+func (a *Archive) ExpiresAt(retention time.Duration) time.Time {
+	return a.ArchivedAt.Add(retention)
+}
+
+// trashFile is the on-disk layout of the trash.
+type trashFile struct {
+	Version  int        `json:"version"`
+	Archives []*Archive `json:"archives"`
+}
+
+// TrashStore records the archives of removed worktrees in a JSON file.
+// The archived content itself lives in the Git object database.
+type TrashStore struct {
+	path string
+}
+
+// NewTrashStore returns a store backed by the file at path.
+func NewTrashStore(path string) *TrashStore {
+	return &TrashStore{path: path}
+}
+
+// Load returns all archives, newest first. A missing file yields no archives.
+func (s *TrashStore) Load() ([]*Archive, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var file trashFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse trash %s: %w", s.path, err)
+	}
+
+	slices.SortStableFunc(file.Archives, func(a, b *Archive) int {
+		return b.ArchivedAt.Compare(a.ArchivedAt)
+	})
+	return file.Archives, nil
+}
+
+// Add records a.
+func (s *TrashStore) Add(a *Archive) error {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	archives, err := s.Load()
+	if err != nil {
+		return err
+	}
+	return s.save(append(archives, a))
+}
+
+// Remove forgets the archives whose ref is in refs.
+func (s *TrashStore) Remove(refs ...string) error {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	archives, err := s.Load()
+	if err != nil {
+		return err
+	}
+	return s.save(slices.DeleteFunc(archives, func(a *Archive) bool {
+		return slices.Contains(refs, a.Ref)
+	}))
+}
+
+// save writes archives to the store file.
+func (s *TrashStore) save(archives []*Archive) error {
+	if archives == nil {
+		archives = []*Archive{}
+	}
+
+	data, err := json.MarshalIndent(trashFile{Version: trashVersion, Archives: archives}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+	return nil
+}
+
+// Trash returns the trash of the repository. Like the metadata store it
+// lives in the Git common directory.
+func (m *Manager) Trash(ctx context.Context) (*TrashStore, error) {
+	dir, err := m.GitCommonDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewTrashStore(filepath.Join(dir, "giwo", "trash.json")), nil
+}
+
+// Archives returns the archives of removed worktrees, newest first.
+func (m *Manager) Archives(ctx context.Context) ([]*Archive, error) {
+	trash, err := m.Trash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return trash.Load()
+}
+
+// TrashRetention returns how long archives are kept.
+func (m *Manager) TrashRetention() time.Duration {
+	return time.Duration(m.cfg.Trash.Retention)
+}
+
+// archiveWorktree snapshots wt, removed as branch, into a commit on top of
+// its HEAD, points a hidden ref at it and records it in the trash.
+// A temporary index is used so that the worktree's own index is untouched.
+func (m *Manager) archiveWorktree(ctx context.Context, branch string, wt *Worktree) (*Archive, error) {
+	path := wt.Path
+	head, err := m.git(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := m.git(ctx, path, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "giwo-archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")}
+	if _, err := m.gitEnv(ctx, path, env, "read-tree", "HEAD"); err != nil {
+		return nil, err
+	}
+	if _, err := m.gitEnv(ctx, path, env, "add", "-A"); err != nil {
+		return nil, err
+	}
+	tree, err := m.gitEnv(ctx, path, env, "write-tree")
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := m.git(ctx, path, "-c", "user.name=giwo", "-c", "user.email=giwo@localhost",
+		"commit-tree", tree, "-p", head, "-m", "giwo archive of "+branch)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	a := &Archive{
+		Branch:     branch,
+		Ref:        archiveRefPrefix + branch + "/" + now.UTC().Format("20060102T150405.000Z"),
+		Head:       head,
+		Commit:     commit,
+		Dirty:      tree != headTree,
+		Detached:   wt.Detached,
+		Path:       path,
+		ArchivedAt: now,
+	}
+	if store, err := m.Metadata(ctx); err == nil {
+		a.Metadata, _ = store.Get(branch)
+	}
+
+	if err := m.runGitCommand(ctx, "update-ref", a.Ref, commit); err != nil {
+		return nil, err
+	}
+
+	trash, err := m.Trash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := trash.Add(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Restore recreates the worktree of the newest archive of branch and
+// reapplies its uncommitted changes and untracked files, which come back
+// unstaged. The branch is recreated at its archived tip if it was deleted.
+// The archive is dropped once the worktree is restored; a restore that fails
+// or is interrupted is rolled back and keeps the archive.
+func (m *Manager) Restore(ctx context.Context, branch string) (*Archive, error) {
+	archives, err := m.Archives(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(archives, func(a *Archive) bool { return a.Branch == branch })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", errors.ErrArchiveNotFound, branch)
+	}
+	a := archives[i]
+
+	worktreePath, err := m.WorktreePath(branch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	args := []string{"worktree", "add", worktreePath, branch}
+	newBranch := false
+	switch {
+	case a.Detached:
+		args = []string{"worktree", "add", "--detach", worktreePath, a.Head}
+	case !m.BranchExists(ctx, branch):
+		args = []string{"worktree", "add", "-b", branch, worktreePath, a.Head}
+		newBranch = true
+	}
+	undo, err := m.addWorktree(ctx, branch, worktreePath, newBranch, args...)
+	if err != nil {
+		return nil, err
+	}
+	m.revertMetadata(ctx, &undo, branch)
+
+	var base string
+	if a.Metadata != nil {
+		base = a.Metadata.Base
+		md := *a.Metadata
+		if err := m.UpdateMetadata(ctx, branch, func(dst *Metadata) { *dst = md }); err != nil {
+			fmt.Printf("⚠️  Warning: failed to restore worktree metadata: %v\n", err)
+		}
+	}
+
+	// Like setupWorktree, but the archived content wins over copied files
+	if err := m.copyConfigFiles(ctx, worktreePath); err != nil {
+		fmt.Printf("⚠️  Warning: failed to copy config files: %v\n", err)
+	}
+	data, err := m.templateData(ctx, branch, worktreePath, base)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: %v, skipping templates\n", err)
+	} else if err := m.renderTemplates(ctx, worktreePath, data); err != nil {
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	}
+	if m.cfg.Env.Envrc {
+		if err := m.writeEnvrc(ctx, data); err != nil {
+			fmt.Printf("⚠️  Warning: failed to write %s: %v\n", EnvrcFile, err)
+		}
+	}
+	if m.cfg.Bootstrap.Enabled {
+		m.bootstrapNew(ctx, worktreePath)
+	}
+
+	// The archive is kept until the worktree is complete, so that a failed
+	// restore can be retried
+	if a.Dirty {
+		if err := m.reapplyArchive(ctx, a, worktreePath); err != nil {
+			undo.run(ctx)
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		undo.run(ctx)
+		return nil, err
+	}
+
+	if err := m.dropArchives(ctx, a); err != nil {
+		fmt.Printf("⚠️  Warning: failed to drop restored archive: %v\n", err)
+	}
+
+	hc := HookContext{
+		Branch: branch,
+		Path:   worktreePath,
+		Base:   base,
+		Ports:  PortBlock{Start: data.Port, Count: len(data.Ports)},
+	}
+	if err := m.RunHooks(ctx, HookPostCreate, hc); err != nil {
+		return a, err
+	}
+	return a, nil
+}
+
+// reapplyArchive brings the working tree at path to the archived content of
+// a while leaving the index at HEAD.
+func (m *Manager) reapplyArchive(ctx context.Context, a *Archive, path string) error {
+	head, err := m.git(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	// When the branch kept moving after the worktree was removed, replay the
+	// archived changes on top of it instead of rewinding its files.
+	if head == a.Head {
+		_, err = m.git(ctx, path, "read-tree", "--reset", "-u", a.Commit)
+	} else {
+		_, err = m.git(ctx, path, "cherry-pick", "--no-commit", a.Commit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reapply archived changes in %s: %w", path, err)
+	}
+
+	if _, err := m.git(ctx, path, "reset", "-q"); err != nil {
+		return fmt.Errorf("failed to unstage archived changes: %w", err)
+	}
+	return nil
+}
+
+// PurgeArchives deletes the archives taken before cutoff and returns them.
+func (m *Manager) PurgeArchives(ctx context.Context, cutoff time.Time) ([]*Archive, error) {
+	archives, err := m.Archives(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []*Archive
+	for _, a := range archives {
+		if a.ArchivedAt.Before(cutoff) {
+			purged = append(purged, a)
+		}
+	}
+	if len(purged) == 0 {
+		return nil, nil
+	}
+
+	if err := m.dropArchives(ctx, purged...); err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// purgeExpiredArchives deletes the archives older than the retention period.
+// Failures only produce a warning since they never affect the removal.
+func (m *Manager) purgeExpiredArchives(ctx context.Context) {
+	if _, err := m.PurgeArchives(ctx, time.Now().Add(-m.TrashRetention())); err != nil {
+		fmt.Printf("⚠️  Warning: failed to purge expired archives: %v\n", err)
+	}
+}
+
+// dropArchives deletes the refs of archives and forgets them.
+func (m *Manager) dropArchives(ctx context.Context, archives ...*Archive) error {
+	refs := make([]string, len(archives))
+	for i, a := range archives {
+		refs[i] = a.Ref
+		if err := m.runGitCommand(ctx, "update-ref", "-d", a.Ref); err != nil {
+			return err
+		}
+	}
+
+	trash, err := m.Trash(ctx)
+	if err != nil {
+		return err
+	}
+	return trash.Remove(refs...)
+}
@@ -0,0 +1,301 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// runGit runs git in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// writeTestFile writes data to path and fails the test on error.
+func writeTestFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagerRemoveAndRestore(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\n")
+	writeTestFile(t, filepath.Join(repo, "b.txt"), "two\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.WorktreeDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", path)
+	writeTestFile(t, filepath.Join(path, "a.txt"), "one\nedited\n")
+	writeTestFile(t, filepath.Join(path, "new.txt"), "untracked\n")
+	if err := os.Remove(filepath.Join(path, "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UpdateMetadata(ctx, "feature", func(md *worktree.Metadata) {
+		md.Description = "Rework login flow"
+	}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+
+	opts := worktree.RemoveOptions{Yes: true, Accept: worktree.Risks}
+	if err := m.Remove(ctx, "feature", opts); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("worktree still exists after Remove(): %v", err)
+	}
+	if m.BranchExists(ctx, "feature") {
+		t.Fatalf("branch still exists after Remove()")
+	}
+
+	archives, err := m.Archives(ctx)
+	if err != nil {
+		t.Fatalf("Archives() unexpected error: %v", err)
+	}
+	if len(archives) != 1 || archives[0].Branch != "feature" || !archives[0].Dirty {
+		t.Fatalf("Archives() = %+v, want one dirty archive of feature", archives)
+	}
+
+	a, err := m.Restore(ctx, "feature")
+	if err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(archives[0].Ref, a.Ref); diff != "" {
+		t.Errorf("Restore() archive mismatch (-want +got):\n%s", diff)
+	}
+
+	status := runGit(t, path, "status", "--porcelain")
+	if diff := cmp.Diff(" M a.txt\n D b.txt\n?? new.txt\n", status); diff != "" {
+		t.Errorf("restored status mismatch (-want +got):\n%s", diff)
+	}
+
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		t.Fatalf("Metadata() unexpected error: %v", err)
+	}
+	md, err := store.Get("feature")
+	if err != nil || md == nil || md.Description != "Rework login flow" {
+		t.Errorf("restored metadata = %+v, %v, want the archived description", md, err)
+	}
+
+	if archives, err := m.Archives(ctx); err != nil || len(archives) != 0 {
+		t.Errorf("Archives() after Restore() = %+v, %v, want none", archives, err)
+	}
+
+	if _, err := m.Restore(ctx, "feature"); !errors.Is(err, giwoerrors.ErrArchiveNotFound) {
+		t.Errorf("second Restore() error = %v, want %v", err, giwoerrors.ErrArchiveNotFound)
+	}
+}
+
+func TestManagerRestoreRollback(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.WorktreeDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", path)
+	writeTestFile(t, filepath.Join(path, "a.txt"), "edited\n")
+	if err := m.Remove(ctx, "feature", worktree.RemoveOptions{KeepBranch: true, Yes: true, Accept: worktree.Risks}); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	archived := strings.TrimSpace(runGit(t, repo, "rev-parse", "feature"))
+
+	// The branch moved on with a conflicting change
+	runGit(t, repo, "checkout", "-q", "feature")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "moved\n")
+	runGit(t, repo, "commit", "-q", "-am", "move")
+	runGit(t, repo, "checkout", "-q", "main")
+
+	if _, err := m.Restore(ctx, "feature"); err == nil {
+		t.Fatal("Restore() with a conflict error = nil, want an error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("worktree directory still exists after rollback: %v", err)
+	}
+	if list := runGit(t, repo, "worktree", "list", "--porcelain"); strings.Contains(list, path) {
+		t.Errorf("worktree still registered after rollback:\n%s", list)
+	}
+	if archives, err := m.Archives(ctx); err != nil || len(archives) != 1 {
+		t.Fatalf("Archives() after rollback = %+v, %v, want the archive kept", archives, err)
+	}
+
+	// A retry succeeds once the conflict is gone
+	runGit(t, repo, "branch", "-f", "feature", archived)
+	if _, err := m.Restore(ctx, "feature"); err != nil {
+		t.Fatalf("Restore() retry unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(" M a.txt\n", runGit(t, path, "status", "--porcelain")); diff != "" {
+		t.Errorf("restored status mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerRemoveFailureDropsArchive(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// Git refuses to remove worktrees containing submodules without --force
+	path := filepath.Join(m.WorktreeDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", path)
+	sub := filepath.Join(path, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, sub, "init", "-q")
+	runGit(t, sub, "commit", "-q", "--allow-empty", "-m", "sub")
+	runGit(t, path, "-c", "protocol.file.allow=always", "submodule", "add", "-q", "./sub", "sub")
+	runGit(t, path, "commit", "-q", "-m", "add submodule")
+
+	ctx := context.Background()
+	if err := m.Remove(ctx, "feature", worktree.RemoveOptions{KeepBranch: true, Yes: true}); err == nil {
+		t.Fatal("Remove() of a worktree with a submodule error = nil, want an error")
+	}
+	if archives, err := m.Archives(ctx); err != nil || len(archives) != 0 {
+		t.Errorf("Archives() after a failed Remove() = %+v, %v, want none", archives, err)
+	}
+	if refs := runGit(t, repo, "for-each-ref", "refs/giwo/archive"); refs != "" {
+		t.Errorf("archive refs left after a failed Remove():\n%s", refs)
+	}
+}
+
+func TestManagerRemoveNested(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	for name, tt := range map[string]struct {
+		// template returns the worktree_path template and the directory
+		// it puts worktrees in, given a temporary directory
+		template func(tmp string) (string, string)
+	}{
+		"worktree dir": {
+			template: func(tmp string) (string, string) {
+				return "", filepath.Join(tmp, "repo", config.DefaultWorktreeDir)
+			},
+		},
+		"outside repository": {
+			template: func(tmp string) (string, string) {
+				return filepath.Join(tmp, "worktrees", "{repo}", "{branch}"), filepath.Join(tmp, "worktrees", "repo")
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmp := t.TempDir()
+			repo := filepath.Join(tmp, "repo")
+			if err := os.Mkdir(repo, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			runGit(t, repo, "init", "-q", "-b", "main")
+			runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+			cfg := config.Default()
+			cfg.CopyFiles = nil
+			var base string
+			cfg.WorktreePath, base = tt.template(tmp)
+			m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			ctx := context.Background()
+			opts := worktree.CreateOptions{From: "main", Fetch: config.FetchNever}
+			for _, branch := range []string{"feat/a", "feat/b"} {
+				if _, err := m.Create(ctx, branch, opts); err != nil {
+					t.Fatalf("Create(%q) unexpected error: %v", branch, err)
+				}
+			}
+
+			// The parent directory goes with its last worktree, and the
+			// directory the template puts worktrees in stays
+			remove := worktree.RemoveOptions{Yes: true, Accept: worktree.Risks, NoArchive: true}
+			feat := filepath.Join(base, "feat")
+			if err := m.Remove(ctx, "feat/a", remove); err != nil {
+				t.Fatalf("Remove() unexpected error: %v", err)
+			}
+			if _, err := os.Stat(feat); err != nil {
+				t.Errorf("Stat(%s) with a worktree left unexpected error: %v", feat, err)
+			}
+			if err := m.Remove(ctx, "feat/b", remove); err != nil {
+				t.Fatalf("Remove() unexpected error: %v", err)
+			}
+			if _, err := os.Stat(feat); !os.IsNotExist(err) {
+				t.Errorf("empty %s still exists after Remove(): %v", feat, err)
+			}
+			if _, err := os.Stat(base); err != nil {
+				t.Errorf("Stat(%s) unexpected error: %v", base, err)
+			}
+		})
+	}
+}
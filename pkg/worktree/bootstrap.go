@@ -0,0 +1,315 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+// Ecosystem is a package manager whose dependencies giwo bootstraps.
+type Ecosystem struct {
+	// Name identifies the ecosystem in the bootstrap.install setting.
+	Name string
+	// Lockfile is the file at the worktree root that pins the dependencies.
+	Lockfile string
+	// Dir is the directory the dependencies are installed into.
+	Dir string
+	// Install is the default command installing the dependencies into Dir.
+	Install string
+	// CopyOnly means the files of Dir are modified in place by the tools,
+	// so they are always copied rather than hardlinked.
+	CopyOnly bool
+	// Relocate means Dir embeds its own absolute path, which is rewritten
+	// when it is reused.
+	Relocate bool
+}
+
+// Ecosystems lists the supported ecosystems. When several ecosystems share
+// a directory, the first one whose lockfile exists is used.
+var Ecosystems = []Ecosystem{
+	{Name: "pnpm", Lockfile: "pnpm-lock.yaml", Dir: "node_modules", Install: "pnpm install --frozen-lockfile"},
+	{Name: "yarn", Lockfile: "yarn.lock", Dir: "node_modules", Install: "yarn install --frozen-lockfile"},
+	{Name: "npm", Lockfile: "package-lock.json", Dir: "node_modules", Install: "npm ci"},
+	{Name: "go", Lockfile: "go.sum", Dir: "vendor", Install: "go mod download"},
+	{Name: "poetry", Lockfile: "poetry.lock", Dir: ".venv", Install: "POETRY_VIRTUALENVS_IN_PROJECT=true poetry install", Relocate: true},
+	{Name: "pip", Lockfile: "requirements.txt", Dir: ".venv", Install: "python3 -m venv .venv && .venv/bin/pip install -r requirements.txt", Relocate: true},
+	{Name: "cargo", Lockfile: "Cargo.lock", Dir: "target", Install: "cargo fetch", CopyOnly: true},
+}
+
+// BootstrapAction is what bootstrapping did for an ecosystem.
+type BootstrapAction string
+
+// Bootstrap action constants.
+const (
+	// BootstrapReused means the dependency directory was reused from
+	// another worktree with an identical lockfile.
+	BootstrapReused BootstrapAction = "reused"
+	// BootstrapInstalled means the install command was run.
+	BootstrapInstalled BootstrapAction = "installed"
+	// BootstrapPresent means the worktree already had the directory.
+	BootstrapPresent BootstrapAction = "present"
+)
+
+// BootstrapResult reports how the dependencies of an ecosystem were set up.
+type BootstrapResult struct {
+	Ecosystem string
+	Action    BootstrapAction
+	// Source is the worktree the directory was reused from.
+	Source string
+	// Err is why the ecosystem could not be bootstrapped.
+	Err error
+}
+
+// Bootstrap sets up the dependencies of the worktree at path for every
+// ecosystem whose lockfile it has. A dependency directory is reused from
+// the main worktree or another worktree whose lockfile is identical, and
+// only if there is none the install command is run, with its output
+// streamed to stderr. A failing ecosystem does not stop the others; the
+// returned error joins their failures.
+func (m *Manager) Bootstrap(ctx context.Context, path string) ([]BootstrapResult, error) {
+	ecosystems, err := m.ecosystems()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []BootstrapResult
+	var errs []error
+	done := make(map[string]bool)
+	for _, eco := range ecosystems {
+		if done[eco.Dir] {
+			continue
+		}
+		sum, err := fileSum(filepath.Join(path, eco.Lockfile))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		done[eco.Dir] = true
+
+		result := BootstrapResult{Ecosystem: eco.Name}
+		if err == nil {
+			result.Action, result.Source, result.Err = m.bootstrapEcosystem(ctx, eco, path, sum, worktrees)
+		} else {
+			result.Err = err
+		}
+		if result.Err != nil {
+			result.Err = fmt.Errorf("%s: %w", eco.Name, result.Err)
+			errs = append(errs, result.Err)
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
+
+// bootstrapNew bootstraps a newly created worktree at path. Failures only
+// produce a warning since the worktree itself is usable and bootstrapping
+// can be retried.
+func (m *Manager) bootstrapNew(ctx context.Context, path string) {
+	if _, err := m.Bootstrap(ctx, path); err != nil && ctx.Err() == nil {
+		fmt.Printf("⚠️  Warning: failed to bootstrap dependencies, retry with 'giwo bootstrap': %v\n", err)
+	}
+}
+
+// ecosystems returns the supported ecosystems with the install commands
+// configured in bootstrap.install.
+func (m *Manager) ecosystems() ([]Ecosystem, error) {
+	ecosystems := append([]Ecosystem(nil), Ecosystems...)
+	for name, install := range m.cfg.Bootstrap.Install {
+		i := indexOfEcosystem(ecosystems, name)
+		if i < 0 {
+			return nil, fmt.Errorf("bootstrap.install: unknown ecosystem %q", name)
+		}
+		ecosystems[i].Install = install
+	}
+	return ecosystems, nil
+}
+
+func indexOfEcosystem(ecosystems []Ecosystem, name string) int {
+	for i, eco := range ecosystems {
+		if eco.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// bootstrapEcosystem sets up the dependency directory of eco in the
+// worktree at path, whose lockfile has the checksum sum.
+func (m *Manager) bootstrapEcosystem(ctx context.Context, eco Ecosystem, path string, sum []byte, worktrees []*Worktree) (BootstrapAction, string, error) {
+	dst := filepath.Join(path, eco.Dir)
+	if _, err := os.Lstat(dst); err == nil {
+		return BootstrapPresent, "", nil
+	}
+
+	for _, wt := range worktrees {
+		if wt.Bare || wt.Prunable || wt.Path == path {
+			continue
+		}
+		src := filepath.Join(wt.Path, eco.Dir)
+		if info, err := os.Stat(src); err != nil || !info.IsDir() {
+			continue
+		}
+		if other, err := fileSum(filepath.Join(wt.Path, eco.Lockfile)); err != nil || !bytes.Equal(other, sum) {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "📦 Reusing %s from %s (%s)\n", eco.Dir, wt.Path, eco.Name)
+		if err := m.reuseDir(eco, wt.Path, path); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to reuse %s: %v\n", src, err)
+			continue
+		}
+		return BootstrapReused, wt.Path, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "📦 Installing %s dependencies: %s\n", eco.Name, eco.Install)
+	cmd := exec.CommandContext(ctx, "sh", "-c", eco.Install)
+	cmd.Dir = path
+	killProcessGroupOnCancel(cmd)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("%q failed: %w", eco.Install, err)
+	}
+	return BootstrapInstalled, "", nil
+}
+
+// reuseDir shares the dependency directory of eco of the worktree at
+// srcRoot with the worktree at dstRoot. The directory is assembled next to
+// its destination and renamed into place, so that an interrupted bootstrap
+// never leaves a partial directory behind.
+func (m *Manager) reuseDir(eco Ecosystem, srcRoot, dstRoot string) error {
+	src := filepath.Join(srcRoot, eco.Dir)
+	dst := filepath.Join(dstRoot, eco.Dir)
+
+	tmp, err := os.MkdirTemp(dstRoot, "."+filepath.Base(eco.Dir)+".giwo-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	link := m.cfg.Bootstrap.Mode == config.BootstrapHardlink && !eco.CopyOnly
+	if err := cloneTree(src, tmp, link); err != nil {
+		return err
+	}
+	if eco.Relocate {
+		if err := relocateTree(tmp, srcRoot, dstRoot); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, dst)
+}
+
+// cloneTree recreates the directory src at dst, which must exist. Regular
+// files are hardlinked if link is set and copied otherwise or where linking
+// fails, for example across file systems. Symlinks are recreated as they are.
+func cloneTree(src, dst string, link bool) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			dest, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(dest, target)
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			if link && os.Link(p, target) == nil {
+				return nil
+			}
+			return copyFile(p, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// relocateTree rewrites the absolute paths of the worktree at oldRoot to
+// newRoot in the files of dir that embed them, such as the scripts and
+// activation files of a Python virtual environment and the .pth files of
+// editable installs. Rewritten files are replaced rather than modified, so
+// that hardlinked originals stay intact.
+func relocateTree(dir, oldRoot, newRoot string) error {
+	pattern := regexp.MustCompile(regexp.QuoteMeta(oldRoot) + `([/"'\s]|$)`)
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !embedsPaths(dir, p) {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		// Binaries would break if their paths changed length
+		if bytes.IndexByte(data, 0) >= 0 || !pattern.Match(data) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		data = pattern.ReplaceAll(data, []byte(strings.ReplaceAll(newRoot, "$", "$$")+"${1}"))
+		os.Remove(p)
+		return os.WriteFile(p, data, info.Mode().Perm())
+	})
+}
+
+// embedsPaths reports whether the file p of the virtual environment at dir
+// may hold absolute paths of the worktree.
+func embedsPaths(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(p)
+	return strings.HasPrefix(rel, "bin/") ||
+		strings.HasPrefix(rel, "Scripts/") ||
+		strings.HasSuffix(name, ".pth") ||
+		strings.HasPrefix(name, "__editable__") ||
+		name == "direct_url.json"
+}
+
+// fileSum returns the SHA-256 checksum of the file at path.
+func fileSum(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
@@ -0,0 +1,140 @@
+package worktree_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestManagerCreateBootstraps(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "node_modules/\n.venv/\ntarget/\n.worktree/\n")
+	writeTestFile(t, filepath.Join(repo, "package-lock.json"), `{"lockfileVersion": 3}`+"\n")
+	writeTestFile(t, filepath.Join(repo, "requirements.txt"), "requests==2.32.3\n")
+	writeTestFile(t, filepath.Join(repo, "Cargo.lock"), "version = 4\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	// A branch whose lockfile differs needs a fresh install
+	runGit(t, repo, "checkout", "-q", "-b", "bumped")
+	writeTestFile(t, filepath.Join(repo, "package-lock.json"), `{"lockfileVersion": 3, "packages": {}}`+"\n")
+	runGit(t, repo, "commit", "-q", "-am", "bump")
+	runGit(t, repo, "checkout", "-q", "main")
+
+	for _, dir := range []string{"node_modules/left-pad", "node_modules/.bin", ".venv/bin", ".venv/lib/site-packages", "target/debug"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(repo, "node_modules", "left-pad", "index.js"), "module.exports = pad\n")
+	if err := os.Symlink("../left-pad/index.js", filepath.Join(repo, "node_modules", ".bin", "left-pad")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(repo, "target", "debug", "app"), "binary\n")
+	writeTestFile(t, filepath.Join(repo, ".venv", "bin", "activate"), "VIRTUAL_ENV='"+repo+"/.venv'\n")
+	writeTestFile(t, filepath.Join(repo, ".venv", "lib", "site-packages", "app.pth"), repo+"\n"+repo+"-other\n")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.Bootstrap.Enabled = true
+	cfg.Bootstrap.Install = map[string]string{
+		"npm": "mkdir -p node_modules && echo bumped > node_modules/installed",
+		"pip": "exit 1",
+	}
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := m.Create(ctx, "feature", worktree.CreateOptions{From: "main", Fetch: config.FetchNever}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if _, err := m.Create(ctx, "bumped", worktree.CreateOptions{Fetch: config.FetchNever}); err != nil {
+		t.Fatalf("Create() of an existing branch unexpected error: %v", err)
+	}
+	feature := filepath.Join(m.WorktreeDir(), "feature")
+	bumped := filepath.Join(m.WorktreeDir(), "bumped")
+
+	// node_modules is hardlinked from the main worktree
+	src, err := os.Stat(filepath.Join(repo, "node_modules", "left-pad", "index.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := os.Stat(filepath.Join(feature, "node_modules", "left-pad", "index.js"))
+	if err != nil {
+		t.Fatalf("Stat() unexpected error: %v", err)
+	}
+	if !os.SameFile(src, dst) {
+		t.Errorf("node_modules/left-pad/index.js is not hardlinked to the main worktree")
+	}
+	if link, err := os.Readlink(filepath.Join(feature, "node_modules", ".bin", "left-pad")); err != nil || link != "../left-pad/index.js" {
+		t.Errorf("Readlink(.bin/left-pad) = %q, %v, want %q", link, err, "../left-pad/index.js")
+	}
+
+	// Cargo's target is copied since builds modify it in place
+	src, err = os.Stat(filepath.Join(repo, "target", "debug", "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err = os.Stat(filepath.Join(feature, "target", "debug", "app"))
+	if err != nil {
+		t.Fatalf("Stat() unexpected error: %v", err)
+	}
+	if os.SameFile(src, dst) {
+		t.Errorf("target/debug/app is hardlinked to the main worktree, want a copy")
+	}
+
+	// .venv is relocated without touching the main worktree's files
+	for path, want := range map[string]string{
+		filepath.Join(feature, ".venv", "bin", "activate"):                 "VIRTUAL_ENV='" + feature + "/.venv'\n",
+		filepath.Join(feature, ".venv", "lib", "site-packages", "app.pth"): feature + "\n" + repo + "-other\n",
+		filepath.Join(repo, ".venv", "bin", "activate"):                    "VIRTUAL_ENV='" + repo + "/.venv'\n",
+		filepath.Join(bumped, "node_modules", "installed"):                 "bumped\n",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("ReadFile() unexpected error: %v", err)
+			continue
+		}
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", path, diff)
+		}
+	}
+
+	// Directories a worktree already has are kept, and a failing install
+	// is reported without stopping the others
+	for _, dir := range []string{feature, bumped, repo} {
+		if err := os.RemoveAll(filepath.Join(dir, ".venv")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := m.Bootstrap(ctx, feature)
+	if err == nil {
+		t.Errorf("Bootstrap() with a failing install error = nil, want an error")
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Ecosystem+":"+string(r.Action))
+	}
+	if diff := cmp.Diff([]string{"npm:present", "pip:", "cargo:present"}, got); diff != "" {
+		t.Errorf("Bootstrap() results mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/forge"
+)
+
+// pullRequestCache is the on-disk cache of the open pull requests of the forge.
+type pullRequestCache struct {
+	RemoteURL    string               `json:"remote_url"`
+	FetchedAt    time.Time            `json:"fetched_at"`
+	PullRequests []*forge.PullRequest `json:"pull_requests"`
+}
+
+// OpenPullRequests returns the open pull requests of the forge.
+// Results younger than maxAge are served from a cache in the Git common
+// directory so that repeated calls, such as shell completion on every
+// keypress, do not hit the forge API.
+func (m *Manager) OpenPullRequests(ctx context.Context, maxAge time.Duration) ([]*forge.PullRequest, error) {
+	f, err := m.Forge()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteURL, err := m.RemoteURL()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := m.GitCommonDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "giwo", "cache", "pull-requests.json")
+
+	if data, err := os.ReadFile(path); err == nil {
+		var cache pullRequestCache
+		if json.Unmarshal(data, &cache) == nil && cache.RemoteURL == remoteURL && time.Since(cache.FetchedAt) < maxAge {
+			return cache.PullRequests, nil
+		}
+	}
+
+	prs, err := f.OpenPullRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is an optimization, so failing to write it is not an error
+	data, err := json.Marshal(pullRequestCache{RemoteURL: remoteURL, FetchedAt: time.Now(), PullRequests: prs})
+	if err == nil && os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+		_ = os.WriteFile(path, data, 0o644)
+	}
+
+	return prs, nil
+}
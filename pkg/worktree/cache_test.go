@@ -0,0 +1,82 @@
+package worktree_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/forge"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/knwoop/giwo/pkg/worktree/worktreetest"
+)
+
+// stubForge is a forge serving fixed lists of open and merged pull requests.
+type stubForge struct {
+	forge.Forge
+
+	open   []*forge.PullRequest
+	merged map[string]*forge.PullRequest
+	calls  int
+}
+
+func (f *stubForge) Authenticated() bool {
+	return true
+}
+
+func (f *stubForge) DefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (f *stubForge) OpenPullRequests(ctx context.Context) ([]*forge.PullRequest, error) {
+	f.calls++
+	return f.open, nil
+}
+
+func (f *stubForge) MergedPullRequest(ctx context.Context, branch string) (*forge.PullRequest, error) {
+	return f.merged[branch], nil
+}
+
+func TestManagerOpenPullRequests(t *testing.T) {
+	t.Parallel()
+
+	f := &stubForge{open: []*forge.PullRequest{{Number: 42, Title: "Add login", HeadRef: "login"}}}
+
+	runner := worktreetest.NewFakeRunner()
+	runner.On("rev-parse", "--show-toplevel").Return(fakeRepoRoot)
+	runner.On("rev-parse", "--git-common-dir").Return(t.TempDir())
+	runner.On("remote", "get-url", "origin").Return("git@github.com:knwoop/giwo.git")
+
+	m, err := worktree.New(worktree.WithGitRunner(runner), worktree.WithConfig(config.Default()), worktree.WithForge(f))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		prs, err := m.OpenPullRequests(ctx, time.Hour)
+		if err != nil {
+			t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(f.open, prs); diff != "" {
+			t.Errorf("OpenPullRequests() mismatch (-want +got):\n%s", diff)
+		}
+	}
+	if f.calls != 1 {
+		t.Errorf("forge called %d times, want 1 with a warm cache", f.calls)
+	}
+
+	// A remote pointing elsewhere invalidates the cache
+	runner.On("remote", "get-url", "origin").Return("git@github.com:someone/fork.git")
+	if _, err := m.OpenPullRequests(ctx, time.Hour); err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if _, err := m.OpenPullRequests(ctx, 0); err != nil {
+		t.Fatalf("OpenPullRequests() unexpected error: %v", err)
+	}
+	if f.calls != 3 {
+		t.Errorf("forge called %d times, want 3 after invalidation and expiry", f.calls)
+	}
+}
@@ -0,0 +1,325 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// copyEntry is a file or directory of the main worktree that is copied or
+// linked into new worktrees.
+type copyEntry struct {
+	// path is slash-separated and relative to the repository root.
+	path    string
+	symlink bool
+}
+
+// copyConfigFiles copies the files and directories selected by copy_files
+// into the worktree at destPath, and links those selected by symlink_files
+// back to the main worktree.
+func (m *Manager) copyConfigFiles(ctx context.Context, destPath string) error {
+	entries, err := m.copyEntries(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Copy first, so that links win over files of copied directories
+	for _, symlink := range []bool{false, true} {
+		for _, entry := range entries {
+			if entry.symlink != symlink {
+				continue
+			}
+
+			src := filepath.Join(m.repoRoot, filepath.FromSlash(entry.path))
+			dst := filepath.Join(destPath, filepath.FromSlash(entry.path))
+			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				return err
+			}
+			if symlink {
+				err = linkPath(src, dst)
+			} else {
+				err = m.copyPath(src, dst, entry.path)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyEntries resolves copy_files and symlink_files to the existing paths of
+// the main worktree that are not excluded by copy_exclude. Plain paths are
+// looked up directly, while glob patterns are matched against the files and
+// directories git ignores. A path selected by both lists is linked.
+func (m *Manager) copyEntries(ctx context.Context) ([]copyEntry, error) {
+	var entries []copyEntry
+	index := make(map[string]int)
+	add := func(p string, symlink bool) {
+		if m.excluded(p) || m.isNestedWorktree(p) {
+			return
+		}
+		if i, ok := index[p]; ok {
+			entries[i].symlink = entries[i].symlink || symlink
+			return
+		}
+		index[p] = len(entries)
+		entries = append(entries, copyEntry{path: p, symlink: symlink})
+	}
+
+	var ignored []string
+	for _, list := range []struct {
+		patterns []string
+		symlink  bool
+	}{
+		{m.cfg.CopyFiles, false},
+		{m.cfg.SymlinkFiles, true},
+	} {
+		for _, pattern := range list.patterns {
+			pattern = path.Clean(pattern)
+			if !hasGlobMeta(pattern) {
+				if _, err := os.Lstat(filepath.Join(m.repoRoot, filepath.FromSlash(pattern))); err == nil {
+					add(pattern, list.symlink)
+				}
+				continue
+			}
+
+			if ignored == nil {
+				var err error
+				if ignored, err = m.ignoredPaths(ctx); err != nil {
+					return nil, err
+				}
+			}
+			for _, p := range ignored {
+				dir := strings.HasSuffix(p, "/")
+				p = strings.TrimSuffix(p, "/")
+				if matchGlob(pattern, p) {
+					add(p, list.symlink)
+				} else if dir && matchesBelow(pattern, p) {
+					if err := m.walkMatches(pattern, p, func(p string) { add(p, list.symlink) }); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// ignoredPaths lists the paths of the main worktree that git ignores.
+// Directories that are ignored as a whole are listed once with a trailing
+// slash.
+func (m *Manager) ignoredPaths(ctx context.Context) ([]string, error) {
+	output, err := m.runner.Run(ctx, GitCommand{
+		Dir:  m.repoRoot,
+		Args: []string{"ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ignored files: %w", err)
+	}
+
+	var paths []string
+	for _, p := range strings.Split(string(output), "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// walkMatches calls fn with the paths inside the ignored directory dir that
+// match pattern. Matching directories are reported without their contents.
+func (m *Manager) walkMatches(pattern, dir string, fn func(string)) error {
+	root := filepath.Join(m.repoRoot, filepath.FromSlash(dir))
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.repoRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchGlob(pattern, rel) {
+			fn(rel)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && (!matchesBelow(pattern, rel) || m.isNestedWorktree(rel)) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// isNestedWorktree reports whether the path p of the main worktree holds
+// other worktrees or repositories, such as the worktree directory, which are
+// never copied.
+func (m *Manager) isNestedWorktree(p string) bool {
+	abs := filepath.Join(m.repoRoot, filepath.FromSlash(p))
+	if isWithin(abs, m.worktreeDir) || isWithin(m.worktreeDir, abs) {
+		return true
+	}
+	_, err := os.Lstat(filepath.Join(abs, ".git"))
+	return err == nil
+}
+
+// excluded reports whether p, or a directory containing it, matches
+// copy_exclude.
+func (m *Manager) excluded(p string) bool {
+	for _, pattern := range m.cfg.CopyExclude {
+		pattern = path.Clean(pattern)
+		for dir := p; dir != "."; dir = path.Dir(dir) {
+			if matchGlob(pattern, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyPath copies the file, directory or symlink at src to dst, preserving
+// file modes. rel is the path of src relative to the repository root and is
+// used to skip what copy_exclude matches inside directories.
+func (m *Manager) copyPath(src, dst, rel string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		sub, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if p != src && m.excluded(path.Join(rel, filepath.ToSlash(sub))) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, sub)
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			os.Remove(target)
+			return os.Symlink(link, target)
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			return copyFile(p, target, info.Mode().Perm())
+		default:
+			// Sockets, pipes and devices are not copied
+			return nil
+		}
+	})
+}
+
+// linkPath replaces dst with a symlink to src.
+func linkPath(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("cannot replace %s with a symlink: %w", dst, err)
+		}
+	}
+	return os.Symlink(src, dst)
+}
+
+// copyFile copies the regular file src to dst with the permissions perm.
+// An existing dst is replaced rather than written through, in case it is a
+// symlink.
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	os.Remove(dst)
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// The umask may have dropped permission bits
+	return os.Chmod(dst, perm)
+}
+
+// hasGlobMeta reports whether pattern contains glob metacharacters.
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// matchGlob reports whether the slash-separated path name matches pattern.
+// Each element of pattern is matched with path.Match, except that ** matches
+// any number of path elements, including none.
+func matchGlob(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(name) + 1 {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchesBelow reports whether pattern may match paths inside the directory
+// dir.
+func matchesBelow(pattern, dir string) bool {
+	elems, dirElems := strings.Split(pattern, "/"), strings.Split(dir, "/")
+	for _, elem := range dirElems {
+		if len(elems) == 0 {
+			return false
+		}
+		if elems[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(elems[0], elem); !ok {
+			return false
+		}
+		elems = elems[1:]
+	}
+	return len(elems) > 0
+}
@@ -0,0 +1,99 @@
+package worktree_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestManagerCreateCopiesFiles(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), ".env\n.vscode/\nconfig/local/\n*.pem\n.worktree/\n")
+	runGit(t, repo, "add", ".gitignore")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	for _, dir := range []string{".vscode", "config/local/cache", "certs"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(repo, ".env"), "SECRET=1\n")
+	writeTestFile(t, filepath.Join(repo, ".vscode", "settings.json"), "{}\n")
+	writeTestFile(t, filepath.Join(repo, "config", "local", "app.yml"), "debug: true\n")
+	writeTestFile(t, filepath.Join(repo, "config", "local", "cache", "blob"), "cached\n")
+	writeTestFile(t, filepath.Join(repo, "certs", "dev.pem"), "key\n")
+	writeTestFile(t, filepath.Join(repo, "notes.txt"), "untracked but not ignored\n")
+	if err := os.Chmod(filepath.Join(repo, "config", "local", "app.yml"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.CopyFiles = []string{".vscode", "config/**", "**/*.pem", "*.txt", "missing/file"}
+	cfg.SymlinkFiles = []string{".env"}
+	cfg.CopyExclude = []string{"config/local/cache"}
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// The second worktree must not pick up files of the first one, which
+	// lives in the ignored worktree directory
+	var path string
+	for _, branch := range []string{"feature", "second"} {
+		if _, err := m.Create(ctx, branch, worktree.CreateOptions{From: "main", Fetch: config.FetchNever}); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", branch, err)
+		}
+		path = filepath.Join(m.WorktreeDir(), branch)
+
+		var got []string
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Name() == ".git" {
+				return nil
+			}
+			if !d.IsDir() {
+				rel, _ := filepath.Rel(path, p)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{".env", ".gitignore", ".vscode/settings.json", "certs/dev.pem", "config/local/app.yml"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("files of %s mismatch (-want +got):\n%s", branch, diff)
+		}
+	}
+
+	if link, err := os.Readlink(filepath.Join(path, ".env")); err != nil || link != filepath.Join(repo, ".env") {
+		t.Errorf("Readlink(.env) = %q, %v, want a link to %s", link, err, filepath.Join(repo, ".env"))
+	}
+	info, err := os.Stat(filepath.Join(path, "config", "local", "app.yml"))
+	if err != nil {
+		t.Fatalf("Stat() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(os.FileMode(0o600), info.Mode().Perm()); diff != "" {
+		t.Errorf("config/local/app.yml mode mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,267 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// EnvFormat is a syntax the environment of a worktree is printed in.
+type EnvFormat string
+
+// Env format constants.
+const (
+	// EnvFormatSh prints export statements for POSIX shells, bash and zsh.
+	EnvFormatSh EnvFormat = "sh"
+	// EnvFormatFish prints set statements for fish.
+	EnvFormatFish EnvFormat = "fish"
+	// EnvFormatDotenv prints a .env file as read by Docker Compose.
+	EnvFormatDotenv EnvFormat = "dotenv"
+	// EnvFormatJSON prints a JSON object.
+	EnvFormatJSON EnvFormat = "json"
+)
+
+// EnvFormats lists the valid env formats.
+var EnvFormats = []EnvFormat{EnvFormatSh, EnvFormatFish, EnvFormatDotenv, EnvFormatJSON}
+
+// EnvrcFile is the direnv file written into new worktrees when env.envrc is
+// set.
+const EnvrcFile = ".envrc"
+
+// EnvVar is an environment variable of a worktree.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// Env returns the environment of the worktree wt, whose branch, or name if it
+// is detached, is name. The index and ports recorded for the worktree are
+// used as they are, so that printing the environment changes nothing; with
+// assign, a linked worktree that has none yet is assigned them. Variables
+// configured in env.vars come last, in name order, and replace giwo's own.
+func (m *Manager) Env(ctx context.Context, wt *Worktree, name string, assign bool) ([]EnvVar, error) {
+	if wt.IsMain {
+		return m.envVars(m.newTemplateData(name, wt.Path, ""))
+	}
+
+	var md *Metadata
+	if store, err := m.Metadata(ctx); err == nil {
+		md, _ = store.Get(name)
+	}
+	if md == nil {
+		md = &Metadata{}
+	}
+	if assign {
+		data, err := m.templateData(ctx, name, wt.Path, md.Base)
+		if err != nil {
+			return nil, err
+		}
+		return m.envVars(data)
+	}
+
+	data := m.newTemplateData(name, wt.Path, md.Base)
+	data.Index = md.Index
+	data.Port, data.Ports = md.Ports.Start, md.Ports.Ports()
+	return m.envVars(data)
+}
+
+// envVars returns the environment of the worktree described by data.
+func (m *Manager) envVars(data TemplateData) ([]EnvVar, error) {
+	vars := []EnvVar{
+		{"GIWO_BRANCH", data.Branch},
+		{"GIWO_WORKTREE", data.Path},
+		{"GIWO_REPO_ROOT", data.RepoRoot},
+	}
+	if data.Index > 0 {
+		vars = append(vars, EnvVar{"GIWO_INDEX", strconv.Itoa(data.Index)})
+	}
+	if len(data.Ports) > 0 {
+		vars = append(vars,
+			EnvVar{"GIWO_PORT", strconv.Itoa(data.Port)},
+			EnvVar{"GIWO_PORT_COUNT", strconv.Itoa(len(data.Ports))},
+		)
+	}
+	// The main worktree keeps the project name Compose derives from its
+	// directory, so that its existing containers stay its own
+	project := data.Repo
+	if data.Path != data.RepoRoot {
+		project += "-" + data.Slug
+	}
+	vars = append(vars, EnvVar{"COMPOSE_PROJECT_NAME", ComposeProjectName(project)})
+
+	for _, name := range slices.Sorted(maps.Keys(m.cfg.Env.Vars)) {
+		value, err := renderString(name, m.cfg.Env.Vars[name], data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render env.vars.%s: %w", name, err)
+		}
+		vars = slices.DeleteFunc(vars, func(v EnvVar) bool { return v.Name == name })
+		vars = append(vars, EnvVar{name, value})
+	}
+	return vars, nil
+}
+
+// renderString renders the template text with data.
+func renderString(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// composeInvalidRegex matches runs of characters Docker Compose does not
+// allow in project names.
+var composeInvalidRegex = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ComposeProjectName turns name into a valid Docker Compose project name:
+// lowercase letters, digits, dashes and underscores, starting with a letter
+// or digit. Other characters become dashes.
+func ComposeProjectName(name string) string {
+	name = composeInvalidRegex.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.TrimLeft(strings.TrimRight(name, "-"), "_-")
+}
+
+// FormatEnv prints vars in format.
+func FormatEnv(vars []EnvVar, format EnvFormat) (string, error) {
+	var b strings.Builder
+	switch format {
+	case EnvFormatSh:
+		for _, v := range vars {
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, shellQuote(v.Value))
+		}
+	case EnvFormatFish:
+		for _, v := range vars {
+			fmt.Fprintf(&b, "set -gx %s %s\n", v.Name, fishQuote(v.Value))
+		}
+	case EnvFormatDotenv:
+		for _, v := range vars {
+			fmt.Fprintf(&b, "%s=%s\n", v.Name, dotenvQuote(v.Value))
+		}
+	case EnvFormatJSON:
+		object := make(map[string]string, len(vars))
+		for _, v := range vars {
+			object[v.Name] = v.Value
+		}
+		data, err := json.MarshalIndent(object, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	default:
+		return "", fmt.Errorf("unknown env format %q", format)
+	}
+	return b.String(), nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, where backslashes and single quotes are
+// escaped inside single quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// dotenvQuote quotes s for .env files unless it consists of plain
+// characters only. Single quotes keep the value literal, and double quotes
+// with escapes are used for values containing single quotes or newlines.
+func dotenvQuote(s string) string {
+	plain := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:@,+", r)
+	}
+	switch {
+	case s != "" && !strings.ContainsFunc(s, func(r rune) bool { return !plain(r) }):
+		return s
+	case !strings.ContainsAny(s, "'\n"):
+		return "'" + s + "'"
+	default:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+}
+
+// writeEnvrc writes the environment of the worktree described by data to its
+// .envrc for direnv and makes git ignore it. A .envrc that is committed to
+// the repository is left alone. The file is not allowed on the user's
+// behalf, so that direnv still asks before running it.
+func (m *Manager) writeEnvrc(ctx context.Context, data TemplateData) error {
+	tracked, err := m.git(ctx, data.Path, "ls-files", "--", EnvrcFile)
+	if err != nil {
+		return err
+	}
+	if tracked != "" {
+		fmt.Printf("⚠️  Warning: %s is committed, add 'eval \"$(giwo env)\"' to it instead\n", EnvrcFile)
+		return nil
+	}
+
+	vars, err := m.envVars(data)
+	if err != nil {
+		return err
+	}
+	exports, err := FormatEnv(vars, EnvFormatSh)
+	if err != nil {
+		return err
+	}
+
+	if err := m.excludeEnvrc(ctx, data.Path); err != nil {
+		return err
+	}
+
+	path := filepath.Join(data.Path, EnvrcFile)
+	// Do not write through a symlink made by symlink_files
+	os.Remove(path)
+	content := "# Generated by giwo; regenerate with 'giwo env > " + EnvrcFile + "'\n" + exports
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath("direnv"); err == nil {
+		fmt.Printf("💡 Run 'direnv allow' in %s to load its environment\n", data.Path)
+	}
+	return nil
+}
+
+// excludeEnvrc adds the .envrc of worktrees to the info/exclude file of the
+// repository unless git already ignores it in the worktree at path, so that
+// generated files are neither committed nor count as untracked files when
+// the worktree is removed.
+func (m *Manager) excludeEnvrc(ctx context.Context, path string) error {
+	if _, err := m.git(ctx, path, "check-ignore", "-q", EnvrcFile); err == nil {
+		return nil
+	}
+
+	dir, err := m.GitCommonDir(ctx)
+	if err != nil {
+		return err
+	}
+	exclude := filepath.Join(dir, "info", "exclude")
+	content, err := os.ReadFile(exclude)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	content = append(content, "/"+EnvrcFile+"\n"...)
+
+	if err := os.MkdirAll(filepath.Dir(exclude), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(exclude, content, 0o644)
+}
@@ -0,0 +1,143 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+func TestManagerEnvVars(t *testing.T) {
+	for name, tt := range map[string]struct {
+		data     TemplateData
+		vars     map[string]string
+		expected []EnvVar
+	}{
+		"linked worktree": {
+			data: TemplateData{
+				Branch: "feature/Login", Slug: "feature-Login", Index: 2, Port: 10010, Ports: []int{10010, 10011},
+				Path: "/src/My App/.worktree/feature/Login", Repo: "My App", RepoRoot: "/src/My App",
+			},
+			expected: []EnvVar{
+				{"GIWO_BRANCH", "feature/Login"},
+				{"GIWO_WORKTREE", "/src/My App/.worktree/feature/Login"},
+				{"GIWO_REPO_ROOT", "/src/My App"},
+				{"GIWO_INDEX", "2"},
+				{"GIWO_PORT", "10010"},
+				{"GIWO_PORT_COUNT", "2"},
+				{"COMPOSE_PROJECT_NAME", "my-app-feature-login"},
+			},
+		},
+		"main worktree with configured vars": {
+			data: TemplateData{Branch: "main", Slug: "main", Path: "/src/app", Repo: "app", RepoRoot: "/src/app"},
+			vars: map[string]string{
+				"DATABASE_URL":         "postgres://localhost/{{.Repo}}_{{.Slug}}",
+				"COMPOSE_PROJECT_NAME": "shared",
+			},
+			expected: []EnvVar{
+				{"GIWO_BRANCH", "main"},
+				{"GIWO_WORKTREE", "/src/app"},
+				{"GIWO_REPO_ROOT", "/src/app"},
+				{"COMPOSE_PROJECT_NAME", "shared"},
+				{"DATABASE_URL", "postgres://localhost/app_main"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.Default()
+			cfg.Env.Vars = tt.vars
+			m := &Manager{repoRoot: tt.data.RepoRoot, cfg: cfg}
+
+			got, err := m.envVars(tt.data)
+			if err != nil {
+				t.Fatalf("envVars() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("envVars() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerEnvVarsBrokenTemplate(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.Env.Vars = map[string]string{"DATABASE": "app_{{.Nope}}"}
+	m := &Manager{repoRoot: "/src/app", cfg: cfg}
+
+	if _, err := m.envVars(TemplateData{Branch: "main", Path: "/src/app"}); err == nil {
+		t.Errorf("envVars() with a broken template error = nil, want an error")
+	}
+}
+
+func TestFormatEnv(t *testing.T) {
+	vars := []EnvVar{
+		{"PLAIN", "feature-login"},
+		{"SPACED", "/src/My App"},
+		{"QUOTED", `it's a "test" \ $HOME`},
+		{"EMPTY", ""},
+	}
+
+	for format, expected := range map[EnvFormat]string{
+		EnvFormatSh: `export PLAIN='feature-login'
+export SPACED='/src/My App'
+export QUOTED='it'\''s a "test" \ $HOME'
+export EMPTY=''
+`,
+		EnvFormatFish: `set -gx PLAIN 'feature-login'
+set -gx SPACED '/src/My App'
+set -gx QUOTED 'it\'s a "test" \\ $HOME'
+set -gx EMPTY ''
+`,
+		EnvFormatDotenv: `PLAIN=feature-login
+SPACED='/src/My App'
+QUOTED="it's a \"test\" \\ $HOME"
+EMPTY=''
+`,
+		EnvFormatJSON: `{
+  "EMPTY": "",
+  "PLAIN": "feature-login",
+  "QUOTED": "it's a \"test\" \\ $HOME",
+  "SPACED": "/src/My App"
+}
+`,
+	} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := FormatEnv(vars, format)
+			if err != nil {
+				t.Fatalf("FormatEnv() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("FormatEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := FormatEnv(vars, "csh"); err == nil {
+		t.Errorf("FormatEnv() with an unknown format error = nil, want an error")
+	}
+}
+
+func TestComposeProjectName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"app-feature-login":  "app-feature-login",
+		"My App-Feature_X":   "my-app-feature_x",
+		"app-fix/#42 (wip)":  "app-fix-42-wip",
+		"-_.hidden-worktree": "hidden-worktree",
+		"日本-app":             "app",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(expected, ComposeProjectName(name)); diff != "" {
+				t.Errorf("ComposeProjectName(%q) mismatch (-want +got):\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+// fetchBase updates the remote-tracking branches of the default remote
+// according to policy before a worktree starts from base. An empty policy
+// means the configured one, and an empty base means the new worktree does
+// not start from a base branch, so the base-only policy fetches nothing.
+func (m *Manager) fetchBase(ctx context.Context, policy config.FetchPolicy, base string) error {
+	if policy == "" {
+		policy = m.cfg.Fetch
+	}
+
+	switch policy {
+	case config.FetchNever:
+		return nil
+	case config.FetchBaseOnly:
+		if base == "" {
+			return nil
+		}
+		refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s", base, m.remoteRef(base))
+		return m.runGitCommand(ctx, "fetch", m.cfg.DefaultRemote, refspec)
+	case config.FetchIfStale:
+		if m.fetchedWithin(ctx, time.Duration(m.cfg.FetchMaxAge)) {
+			return nil
+		}
+	}
+	return m.runGitCommand(ctx, "fetch", "--prune", m.cfg.DefaultRemote)
+}
+
+// fetchedWithin reports whether the repository was fetched less than maxAge
+// ago, judging by the modification time of FETCH_HEAD.
+func (m *Manager) fetchedWithin(ctx context.Context, maxAge time.Duration) bool {
+	dir, err := m.GitCommonDir(ctx)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, "FETCH_HEAD"))
+	if err != nil {
+		return false
+	}
+	return time.Since(info.ModTime()) < maxAge
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package worktree
+
+import "os"
+
+// lockFile is a no-op on platforms without flock.
+func lockFile(f *os.File) error { return nil }
@@ -0,0 +1,141 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// GitCommand describes a single git invocation.
+type GitCommand struct {
+	// Dir is the working directory. Empty means the current directory.
+	Dir string
+
+	// Args are the arguments passed to git, starting with the subcommand.
+	Args []string
+
+	// Env holds extra environment variables in "KEY=value" form.
+	Env []string
+}
+
+// String returns the command line of c.
+func (c GitCommand) String() string {
+	return "git " + strings.Join(c.Args, " ")
+}
+
+// GitRunner executes git commands on behalf of a Manager.
+type GitRunner interface {
+	// Run executes cmd and returns its standard output.
+	// A non-zero exit status is reported as an error.
+	Run(ctx context.Context, cmd GitCommand) ([]byte, error)
+}
+
+// ExecRunner runs git as a subprocess.
+type ExecRunner struct {
+	// Path is the git executable. Empty means "git" looked up in PATH.
+	Path string
+}
+
+// Run implements GitRunner.
+// Failures are reported as *errors.GitError including git's error output.
+func (r ExecRunner) Run(ctx context.Context, c GitCommand) ([]byte, error) {
+	path := r.Path
+	if path == "" {
+		path = "git"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return stdout.Bytes(), newGitError(c.Args, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// TracingRunner decorates a GitRunner and reports every invocation to Trace.
+type TracingRunner struct {
+	Runner GitRunner
+	Trace  func(cmd GitCommand, duration time.Duration, err error)
+}
+
+// NewTracingRunner returns a TracingRunner that logs each invocation to w.
+func NewTracingRunner(runner GitRunner, w io.Writer) *TracingRunner {
+	var mu sync.Mutex
+	return &TracingRunner{
+		Runner: runner,
+		Trace: func(cmd GitCommand, duration time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			status := "ok"
+			if err != nil {
+				status = err.Error()
+			}
+			fmt.Fprintf(w, "[git] %s (dir=%s, %s): %s\n", cmd, cmd.Dir, duration.Round(time.Millisecond), status)
+		},
+	}
+}
+
+// Run implements GitRunner.
+func (r *TracingRunner) Run(ctx context.Context, cmd GitCommand) ([]byte, error) {
+	start := time.Now()
+	output, err := r.Runner.Run(ctx, cmd)
+	if r.Trace != nil {
+		r.Trace(cmd, time.Since(start), err)
+	}
+	return output, err
+}
+
+// newGitError wraps err in a GitError for the git invocation args.
+func newGitError(args []string, err error) error {
+	if len(args) == 0 {
+		return errors.NewGitError("", nil, err)
+	}
+	return errors.NewGitError(args[0], args[1:], err)
+}
+
+// git runs a git command in dir through the Manager's runner and returns its
+// trimmed output.
+func (m *Manager) git(ctx context.Context, dir string, args ...string) (string, error) {
+	return m.gitEnv(ctx, dir, nil, args...)
+}
+
+// gitEnv is like git but adds env, in "KEY=value" form, to the environment
+// of the command.
+func (m *Manager) gitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	output, err := m.runner.Run(ctx, GitCommand{Dir: dir, Args: args, Env: env})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitOutput runs a git command in the repository root and returns its trimmed output.
+func (m *Manager) gitOutput(ctx context.Context, args ...string) (string, error) {
+	return m.git(ctx, m.repoRoot, args...)
+}
+
+// runGitCommand runs a git command in the repository root.
+func (m *Manager) runGitCommand(ctx context.Context, args ...string) error {
+	_, err := m.git(ctx, m.repoRoot, args...)
+	return err
+}
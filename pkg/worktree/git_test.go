@@ -0,0 +1,1099 @@
+package worktree_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/forge"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/knwoop/giwo/pkg/worktree/worktreetest"
+)
+
+const fakeRepoRoot = "/src/repo"
+
+// newFakeManager returns a Manager for fakeRepoRoot backed by runner.
+func newFakeManager(t *testing.T, runner *worktreetest.FakeRunner) *worktree.Manager {
+	t.Helper()
+	return newFakeManagerAt(t, runner, fakeRepoRoot)
+}
+
+// newFakeManagerAt returns a Manager for the repository at root backed by
+// runner. Use it with a temporary root for operations that touch the disk.
+func newFakeManagerAt(t *testing.T, runner *worktreetest.FakeRunner, root string) *worktree.Manager {
+	t.Helper()
+	return newFakeManagerWithConfig(t, runner, root, config.Default())
+}
+
+// newFakeManagerWithConfig is newFakeManagerAt with cfg instead of the
+// default configuration.
+func newFakeManagerWithConfig(t *testing.T, runner *worktreetest.FakeRunner, root string, cfg *config.Config) *worktree.Manager {
+	t.Helper()
+
+	runner.On("rev-parse", "--show-toplevel").Return(root + "\n")
+	runner.On("rev-parse", "--git-common-dir").Return(t.TempDir() + "\n")
+	m, err := worktree.New(
+		worktree.WithGitRunner(runner),
+		worktree.WithConfig(cfg),
+	)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return m
+}
+
+func TestNew(t *testing.T) {
+	for name, tt := range map[string]struct {
+		script  func(r *worktreetest.FakeRunner)
+		want    string
+		wantErr error
+	}{
+		"resolves repository root": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--show-toplevel").Return(fakeRepoRoot + "\n")
+				r.On("rev-parse", "--git-common-dir").Return(".git\n")
+			},
+			want: fakeRepoRoot,
+		},
+		"resolves main worktree from linked worktree": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--show-toplevel").Return(fakeRepoRoot + "/.worktree/feature-auth\n")
+				r.On("rev-parse", "--git-common-dir").Return(fakeRepoRoot + "/.git\n")
+			},
+			want: fakeRepoRoot,
+		},
+		"bare common directory": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--show-toplevel").Return(fakeRepoRoot + "\n")
+				r.On("rev-parse", "--git-common-dir").Return("/src/repo.git\n")
+			},
+			want: fakeRepoRoot,
+		},
+		"outside of a repository": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--show-toplevel").Fail("fatal: not a git repository")
+			},
+			wantErr: giwoerrors.ErrNotGitRepository,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			tt.script(runner)
+
+			m, err := worktree.New(worktree.WithGitRunner(runner), worktree.WithConfig(config.Default()))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, m.RepoRoot()); diff != "" {
+				t.Errorf("RepoRoot() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerList(t *testing.T) {
+	t.Parallel()
+
+	runner := worktreetest.NewFakeRunner()
+	m := newFakeManager(t, runner)
+
+	featurePath := fakeRepoRoot + "/.worktree/feature-auth"
+	runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot) +
+		porcelainEntry(featurePath, "2222222222222222222222222222222222222222", "branch refs/heads/feature-auth"))
+	runner.On("status", "--porcelain").InDir(fakeRepoRoot)
+	runner.On("log", "-1", "--format=%s|%ct").Return("Initial commit|0\n")
+	runner.On("rev-list", "--count", "--left-right", "origin/main...HEAD").Return("0\t0\n")
+	runner.On("status", "--porcelain").InDir(featurePath).Return(" M auth.go\nA  login.go\n")
+	runner.On("rev-list", "--count", "--left-right", "origin/feature-auth...HEAD").Fail("fatal: ambiguous argument")
+
+	if err := m.UpdateMetadata(context.Background(), "feature-auth", func(md *worktree.Metadata) {
+		md.Description = "Rework login flow"
+	}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+
+	worktrees, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+
+	type summary struct {
+		Branch          string
+		IsMain, IsClean bool
+		Modified, Added int
+		LastCommit      string
+		Metadata        *worktree.Metadata
+	}
+	var got []summary
+	for _, wt := range worktrees {
+		got = append(got, summary{wt.Branch, wt.IsMain, wt.IsClean, wt.Modified, wt.Added, wt.LastCommit, wt.Metadata})
+	}
+	want := []summary{
+		{Branch: "main", IsMain: true, IsClean: true, LastCommit: "Initial commit"},
+		{Branch: "feature-auth", Modified: 1, Added: 1, LastCommit: "Initial commit", Metadata: &worktree.Metadata{Description: "Rework login flow"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerListEnrichmentError(t *testing.T) {
+	t.Parallel()
+
+	runner := worktreetest.NewFakeRunner()
+	m := newFakeManager(t, runner)
+
+	brokenPath := fakeRepoRoot + "/.worktree/broken"
+	runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot, brokenPath))
+	runner.On("status", "--porcelain")
+	runner.On("log", "-1", "--format=%s|%ct").Return("Initial commit|0\n")
+	runner.On("rev-list", "--count", "--left-right", "origin/main...HEAD").Return("0\t0\n")
+	runner.On("status", "--porcelain").InDir(brokenPath).Fail("fatal: not a git repository")
+
+	worktrees, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, wt := range worktrees {
+		got[wt.Path] = wt.Error
+	}
+	want := map[string]string{
+		fakeRepoRoot: "",
+		brokenPath:   "git status failed: exit status 1: fatal: not a git repository",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// blockingRunner answers every command after a delay and records the
+// highest number of concurrent invocations.
+type blockingRunner struct {
+	*worktreetest.FakeRunner
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (r *blockingRunner) Run(ctx context.Context, cmd worktree.GitCommand) ([]byte, error) {
+	if cmd.Args[0] == "status" {
+		r.mu.Lock()
+		r.inFlight++
+		r.maxInFlight = max(r.maxInFlight, r.inFlight)
+		r.mu.Unlock()
+
+		defer func() {
+			r.mu.Lock()
+			r.inFlight--
+			r.mu.Unlock()
+		}()
+
+		select {
+		case <-time.After(20 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return r.FakeRunner.Run(ctx, cmd)
+}
+
+func TestManagerListConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	for i := range 6 {
+		paths = append(paths, fmt.Sprintf("%s/.worktree/feature-%d", fakeRepoRoot, i))
+	}
+
+	fake := worktreetest.NewFakeRunner()
+	fake.On("rev-parse", "--show-toplevel").Return(fakeRepoRoot)
+	fake.On("rev-parse", "--git-common-dir").Return(t.TempDir())
+	fake.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(paths...))
+	fake.On("status", "--porcelain")
+	fake.On("log", "-1", "--format=%s|%ct").Return("Initial commit|0")
+	for i := range paths {
+		fake.On("rev-list", "--count", "--left-right", fmt.Sprintf("origin/feature-%d...HEAD", i)).Return("0\t0")
+	}
+	runner := &blockingRunner{FakeRunner: fake}
+
+	cfg := config.Default()
+	cfg.Jobs = 2
+	m, err := worktree.New(worktree.WithGitRunner(runner), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	worktrees, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Error != "" || !wt.IsClean {
+			t.Errorf("worktree %s: IsClean = %v, Error = %q", wt.Path, wt.IsClean, wt.Error)
+		}
+	}
+	if runner.maxInFlight != cfg.Jobs {
+		t.Errorf("max concurrent git commands = %d, want %d", runner.maxInFlight, cfg.Jobs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.List(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("List() with cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// porcelainList returns 'git worktree list --porcelain -z' output for
+// worktrees at paths, each on the branch named after the last path element.
+func porcelainList(paths ...string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		branch := filepath.Base(path)
+		if path == fakeRepoRoot {
+			branch = "main"
+		}
+		b.WriteString(porcelainEntry(path, "1111111111111111111111111111111111111111", "branch refs/heads/"+branch))
+	}
+	return b.String()
+}
+
+// porcelainEntry returns the 'git worktree list --porcelain -z' record of the
+// worktree at path with head checked out and the given attributes.
+func porcelainEntry(path, head string, attrs ...string) string {
+	fields := append([]string{"worktree " + path, "HEAD " + head}, attrs...)
+	return strings.Join(fields, "\x00") + "\x00\x00"
+}
+
+func TestManagerCreateFetch(t *testing.T) {
+	for name, tt := range map[string]struct {
+		opts       worktree.CreateOptions
+		remote     string
+		fetchHead  time.Duration
+		fetchFails bool
+		wantFetch  []string
+		wantAdd    string
+	}{
+		"always": {
+			opts:      worktree.CreateOptions{Fetch: config.FetchAlways},
+			wantFetch: []string{"fetch --prune origin"},
+			wantAdd:   "worktree add -b feature-auth %s origin/develop",
+		},
+		"always from configured remote": {
+			opts:      worktree.CreateOptions{Fetch: config.FetchAlways},
+			remote:    "upstream",
+			wantFetch: []string{"fetch --prune upstream"},
+			wantAdd:   "worktree add -b feature-auth %s upstream/develop",
+		},
+		"base only": {
+			opts:      worktree.CreateOptions{Fetch: config.FetchBaseOnly},
+			wantFetch: []string{"fetch origin +refs/heads/develop:refs/remotes/origin/develop"},
+			wantAdd:   "worktree add -b feature-auth %s origin/develop",
+		},
+		"base only from revision": {
+			opts:    worktree.CreateOptions{Fetch: config.FetchBaseOnly, From: "v1.2.0"},
+			wantAdd: "worktree add -b feature-auth %s v1.2.0",
+		},
+		"if stale with recent fetch": {
+			opts:      worktree.CreateOptions{Fetch: config.FetchIfStale},
+			fetchHead: time.Minute,
+			wantAdd:   "worktree add -b feature-auth %s origin/develop",
+		},
+		"if stale with old fetch": {
+			opts:      worktree.CreateOptions{Fetch: config.FetchIfStale},
+			fetchHead: 2 * time.Hour,
+			wantFetch: []string{"fetch --prune origin"},
+			wantAdd:   "worktree add -b feature-auth %s origin/develop",
+		},
+		"never": {
+			opts:    worktree.CreateOptions{Fetch: config.FetchNever},
+			wantAdd: "worktree add -b feature-auth %s origin/develop",
+		},
+		"offline falls back to local base": {
+			fetchFails: true,
+			wantFetch:  []string{"fetch --prune origin"},
+			wantAdd:    "worktree add -b feature-auth %s develop",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.Default()
+			if tt.remote != "" {
+				cfg.DefaultRemote = tt.remote
+			}
+			root := t.TempDir()
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManagerWithConfig(t, runner, root, cfg)
+			path := filepath.Join(m.WorktreeDir(), "feature-auth")
+
+			if tt.fetchHead > 0 {
+				dir, err := m.GitCommonDir(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				fetchHead := filepath.Join(dir, "FETCH_HEAD")
+				writeTestFile(t, fetchHead, "")
+				modTime := time.Now().Add(-tt.fetchHead)
+				if err := os.Chtimes(fetchHead, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(root))
+			fetch := runner.On("fetch", "--prune", cfg.DefaultRemote)
+			if tt.fetchFails {
+				fetch.Fail("fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com")
+			}
+			runner.On("fetch", "origin", "+refs/heads/develop:refs/remotes/origin/develop")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/heads/develop")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/remotes/"+cfg.DefaultRemote+"/develop")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/heads/feature-auth").Fail("")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/remotes/origin/feature-auth").Fail("")
+			runner.On("rev-parse", "--verify", "--quiet", "v1.2.0^{commit}")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "origin/develop")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "upstream/develop")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "develop")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "v1.2.0")
+			runner.On("ls-files", "-z", "--cached", "--others", "--", ":(glob)**/*.giwo.tmpl")
+
+			tt.opts.Base = "develop"
+			if _, err := m.Create(context.Background(), "feature-auth", tt.opts); err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+
+			var fetches, adds []string
+			for _, line := range runner.CommandLines() {
+				switch {
+				case strings.HasPrefix(line, "fetch"):
+					fetches = append(fetches, line)
+				case strings.HasPrefix(line, "worktree add"):
+					adds = append(adds, line)
+				}
+			}
+			if diff := cmp.Diff(tt.wantFetch, fetches); diff != "" {
+				t.Errorf("fetch mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{fmt.Sprintf(tt.wantAdd, path)}, adds); diff != "" {
+				t.Errorf("worktree add mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerWorktreesDetached(t *testing.T) {
+	t.Parallel()
+
+	runner := worktreetest.NewFakeRunner()
+	m := newFakeManager(t, runner)
+	runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot) +
+		porcelainEntry(fakeRepoRoot+"/.worktree/v1.2.0", "2222222222222222222222222222222222222222", "detached"))
+
+	worktrees, err := m.Worktrees(context.Background())
+	if err != nil {
+		t.Fatalf("Worktrees() unexpected error: %v", err)
+	}
+
+	type summary struct {
+		Name, Branch, Head string
+		Detached           bool
+	}
+	var got []summary
+	for _, wt := range worktrees {
+		got = append(got, summary{wt.Name(), wt.Branch, wt.Head, wt.Detached})
+	}
+	want := []summary{
+		{Name: "main", Branch: "main", Head: "1111111111111111111111111111111111111111"},
+		{Name: "2222222", Head: "2222222222222222222222222222222222222222", Detached: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Worktrees() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerWorktreesWithoutNUL(t *testing.T) {
+	t.Parallel()
+
+	// git before 2.36 does not know -z
+	runner := worktreetest.NewFakeRunner()
+	m := newFakeManager(t, runner)
+	runner.On("worktree", "list", "--porcelain", "-z").Fail("error: unknown switch `z'")
+	runner.On("worktree", "list", "--porcelain").Return(strings.ReplaceAll(porcelainList(fakeRepoRoot, featurePath), "\x00", "\n"))
+
+	worktrees, err := m.Worktrees(context.Background())
+	if err != nil {
+		t.Fatalf("Worktrees() unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, wt := range worktrees {
+		got = append(got, wt.Path)
+	}
+	if diff := cmp.Diff([]string{fakeRepoRoot, featurePath}, got); diff != "" {
+		t.Errorf("Worktrees() paths mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerGetCurrentBranch(t *testing.T) {
+	for name, tt := range map[string]struct {
+		script func(r *worktreetest.FakeRunner)
+		want   string
+	}{
+		"on a branch": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--abbrev-ref", "HEAD").Return("feature-auth\n")
+			},
+			want: "feature-auth",
+		},
+		"detached HEAD": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-parse", "--abbrev-ref", "HEAD").Return("HEAD\n")
+				r.On("describe", "--contains", "--all", "HEAD").Return("heads/main\n")
+			},
+			want: "main",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			tt.script(runner)
+
+			got, err := m.GetCurrentBranch(context.Background())
+			if err != nil {
+				t.Fatalf("GetCurrentBranch() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetCurrentBranch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerBranches(t *testing.T) {
+	for name, tt := range map[string]struct {
+		remotes bool
+		want    []string
+	}{
+		"local": {
+			want: []string{"feature-auth", "main"},
+		},
+		"with remotes": {
+			remotes: true,
+			want:    []string{"feature-auth", "main", "origin/main"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			runner.On("for-each-ref", "--format=%(refname:short)", "refs/heads").Return("feature-auth\nmain\n")
+			runner.On("for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/remotes").Return("feature-auth\nmain\norigin/HEAD\norigin/main\n")
+
+			got, err := m.Branches(context.Background(), tt.remotes)
+			if err != nil {
+				t.Fatalf("Branches() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Branches() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerPrune(t *testing.T) {
+	t.Parallel()
+
+	runner := worktreetest.NewFakeRunner()
+	m := newFakeManager(t, runner)
+	runner.On("worktree", "prune", "-v").Return("Removing worktrees/gone: gitdir file points to non-existent location\n")
+
+	got, err := m.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Prune() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("Removing worktrees/gone: gitdir file points to non-existent location", got); diff != "" {
+		t.Errorf("Prune() mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []string{"rev-parse --show-toplevel", "rev-parse --git-common-dir", "worktree prune -v"}
+	if diff := cmp.Diff(want, runner.CommandLines()); diff != "" {
+		t.Errorf("commands mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFakeRunnerUnexpectedCommand(t *testing.T) {
+	t.Parallel()
+
+	runner := worktreetest.NewFakeRunner()
+	_, err := runner.Run(context.Background(), worktree.GitCommand{Args: []string{"fetch", "--prune"}})
+
+	var gitErr *giwoerrors.GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Run() error = %v, want *GitError", err)
+	}
+	if diff := cmp.Diff("fetch", gitErr.Operation); diff != "" {
+		t.Errorf("Operation mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTracingRunner(t *testing.T) {
+	t.Parallel()
+
+	fake := worktreetest.NewFakeRunner()
+	fake.On("status", "--porcelain").Return(" M auth.go\n")
+
+	var buf bytes.Buffer
+	runner := worktree.NewTracingRunner(fake, &buf)
+
+	output, err := runner.Run(context.Background(), worktree.GitCommand{Dir: "/src/repo", Args: []string{"status", "--porcelain"}})
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(" M auth.go\n", string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := runner.Run(context.Background(), worktree.GitCommand{Dir: "/src/repo", Args: []string{"fetch"}}); err == nil {
+		t.Fatal("Run() expected error for unscripted command")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("trace has %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "[git] git status --porcelain (dir=/src/repo, ") || !strings.HasSuffix(lines[0], "): ok") {
+		t.Errorf("trace line = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[git] git fetch (dir=/src/repo, ") || strings.HasSuffix(lines[1], "): ok") {
+		t.Errorf("trace line = %q", lines[1])
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := worktree.ExecRunner{}.Run(ctx, worktree.GitCommand{
+		Dir:  dir,
+		Args: []string{"rev-parse", "--show-toplevel"},
+		Env:  []string{"GIT_CEILING_DIRECTORIES=" + filepath.Dir(dir)},
+	})
+
+	var gitErr *giwoerrors.GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Run() error = %v, want *GitError", err)
+	}
+	if !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Run() error = %v, want git's error output", err)
+	}
+}
+
+func TestManagerCreate(t *testing.T) {
+	for name, tt := range map[string]struct {
+		script     func(r *worktreetest.FakeRunner, path string)
+		opts       worktree.CreateOptions
+		wantSource worktree.BranchSource
+		wantAdd    string
+		wantErr    error
+	}{
+		"new branch": {
+			script:     func(r *worktreetest.FakeRunner, path string) {},
+			opts:       worktree.CreateOptions{Base: "develop"},
+			wantSource: worktree.BranchSourceNew,
+			wantAdd:    "worktree add -b feature-auth %s origin/develop",
+		},
+		"existing local branch": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "refs/heads/feature-auth")
+			},
+			wantSource: worktree.BranchSourceLocal,
+			wantAdd:    "worktree add %s feature-auth",
+		},
+		"existing remote branch": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "refs/remotes/origin/feature-auth")
+			},
+			wantSource: worktree.BranchSourceRemote,
+			wantAdd:    "worktree add --track -b feature-auth %s origin/feature-auth",
+		},
+		"new ignores remote branch": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "refs/remotes/origin/feature-auth")
+			},
+			opts:       worktree.CreateOptions{New: true},
+			wantSource: worktree.BranchSourceNew,
+			wantAdd:    "worktree add -b feature-auth %s origin/main",
+		},
+		"new with existing local branch": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "refs/heads/feature-auth")
+			},
+			opts:    worktree.CreateOptions{New: true},
+			wantErr: giwoerrors.ErrBranchExists,
+		},
+		"from revision": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "v1.2.0^{commit}")
+				r.On("worktree", "add", "-b", "feature-auth", path, "v1.2.0")
+			},
+			opts:       worktree.CreateOptions{From: "v1.2.0"},
+			wantSource: worktree.BranchSourceNew,
+			wantAdd:    "worktree add -b feature-auth %s v1.2.0",
+		},
+		"detached": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "v1.2.0^{commit}")
+				r.On("worktree", "add", "--detach", path, "v1.2.0")
+			},
+			opts:       worktree.CreateOptions{From: "v1.2.0", Detach: true},
+			wantSource: worktree.BranchSourceDetached,
+			wantAdd:    "worktree add --detach %s v1.2.0",
+		},
+		"invalid revision": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("rev-parse", "--verify", "--quiet", "nope^{commit}").Fail("")
+			},
+			opts:    worktree.CreateOptions{From: "nope"},
+			wantErr: giwoerrors.ErrInvalidRevision,
+		},
+		"checked out elsewhere": {
+			script: func(r *worktreetest.FakeRunner, path string) {
+				r.On("worktree", "list", "--porcelain", "-z").Return(porcelainList("/elsewhere/feature-auth"))
+			},
+			wantErr: giwoerrors.ErrBranchCheckedOut,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManagerAt(t, runner, root)
+			path := filepath.Join(m.WorktreeDir(), "feature-auth")
+
+			runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(root))
+			runner.On("fetch", "--prune", "origin")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/heads/feature-auth").Fail("")
+			runner.On("rev-parse", "--verify", "--quiet", "refs/remotes/origin/feature-auth").Fail("")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "origin/develop")
+			runner.On("worktree", "add", "-b", "feature-auth", path, "origin/main")
+			runner.On("worktree", "add", path, "feature-auth")
+			runner.On("worktree", "add", "--track", "-b", "feature-auth", path, "origin/feature-auth")
+			runner.On("ls-files", "-z", "--cached", "--others", "--", ":(glob)**/*.giwo.tmpl")
+			tt.script(runner, path)
+
+			source, err := m.Create(context.Background(), "feature-auth", tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Create() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantSource, source); diff != "" {
+				t.Errorf("Create() source mismatch (-want +got):\n%s", diff)
+			}
+
+			var adds []string
+			for _, line := range runner.CommandLines() {
+				if strings.HasPrefix(line, "worktree add") {
+					adds = append(adds, line)
+				}
+			}
+			if diff := cmp.Diff([]string{fmt.Sprintf(tt.wantAdd, path)}, adds); diff != "" {
+				t.Errorf("worktree add mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerCreateFromPullRequest(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "-q", "-b", "main")
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, upstream, "checkout", "-q", "-b", "topic")
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "first")
+	runGit(t, upstream, "update-ref", "refs/pull/5/head", "HEAD")
+
+	repo := filepath.Join(t.TempDir(), "clone")
+	runGit(t, upstream, "clone", "-q", upstream, repo)
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	src := worktree.PullRequestSource{Number: 5, Base: "main"}
+	keep := worktree.RemoveOptions{KeepBranch: true, Yes: true, NoArchive: true}
+	head := func() string {
+		return strings.TrimSpace(runGit(t, upstream, "rev-parse", "refs/pull/5/head"))
+	}
+
+	if err := m.CreateFromPullRequest(ctx, src, false); err != nil {
+		t.Fatalf("CreateFromPullRequest() unexpected error: %v", err)
+	}
+
+	// The fetched head survives pruning, so the commits count as pushed
+	runGit(t, repo, "fetch", "-q", "--prune")
+	if diff := cmp.Diff(head(), strings.TrimSpace(runGit(t, repo, "rev-parse", "refs/giwo/pull/5"))); diff != "" {
+		t.Errorf("refs/giwo/pull/5 mismatch (-want +got):\n%s", diff)
+	}
+	findings, err := m.Preflight(ctx, "pr-5", false)
+	if err != nil {
+		t.Fatalf("Preflight() unexpected error: %v", err)
+	}
+	for _, f := range findings {
+		if f.Risk == worktree.RiskUnpushed {
+			t.Errorf("Preflight() reported %s: %s", f.Risk, f.Detail)
+		}
+	}
+
+	// A branch left from an earlier review is fast-forwarded
+	if err := m.Remove(ctx, "pr-5", keep); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "second")
+	runGit(t, upstream, "update-ref", "refs/pull/5/head", "HEAD")
+	if err := m.CreateFromPullRequest(ctx, src, false); err != nil {
+		t.Fatalf("CreateFromPullRequest() again unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(head(), strings.TrimSpace(runGit(t, repo, "rev-parse", "pr-5"))); diff != "" {
+		t.Errorf("pr-5 mismatch (-want +got):\n%s", diff)
+	}
+
+	// and refused once the pull request was force-pushed
+	if err := m.Remove(ctx, "pr-5", keep); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	runGit(t, upstream, "checkout", "-q", "-b", "rewritten", "main")
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "rewritten")
+	runGit(t, upstream, "update-ref", "refs/pull/5/head", "HEAD")
+	if err := m.CreateFromPullRequest(ctx, src, false); !errors.Is(err, giwoerrors.ErrBranchExists) {
+		t.Errorf("CreateFromPullRequest() of a diverged branch error = %v, want %v", err, giwoerrors.ErrBranchExists)
+	}
+}
+
+func TestManagerCreateFromLinkedWorktree(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.Fetch = config.FetchNever
+	root, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	feature := filepath.Join(root.WorktreeDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+	runGit(t, feature, "commit", "-q", "--allow-empty", "-m", "feature work")
+
+	m, err := worktree.New(worktree.WithRepoRoot(feature), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(repo, m.RepoRoot()); diff != "" {
+		t.Errorf("RepoRoot() mismatch (-want +got):\n%s", diff)
+	}
+	ctx := context.Background()
+	current, err := m.CurrentWorktree(ctx)
+	if err != nil {
+		t.Fatalf("CurrentWorktree() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(feature, current.Path); diff != "" {
+		t.Errorf("CurrentWorktree() path mismatch (-want +got):\n%s", diff)
+	}
+	base, err := m.GetCurrentBranch(ctx)
+	if err != nil {
+		t.Fatalf("GetCurrentBranch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("feature", base); diff != "" {
+		t.Errorf("GetCurrentBranch() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The unpushed base starts the new branch from its local tip
+	if _, err := m.Create(ctx, "child", worktree.CreateOptions{Base: base}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	want := runGit(t, repo, "rev-parse", "feature")
+	if diff := cmp.Diff(want, runGit(t, repo, "rev-parse", "child")); diff != "" {
+		t.Errorf("child mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(root.WorktreeDir(), m.WorktreeDir()); diff != "" {
+		t.Errorf("WorktreeDir() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerGetMergedBranches(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "-q", "-b", "main")
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	repo := filepath.Join(t.TempDir(), "clone")
+	runGit(t, upstream, "clone", "-q", upstream, repo)
+
+	f := &stubForge{merged: make(map[string]*forge.PullRequest)}
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg), worktree.WithForge(f))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// commit adds files to a new worktree of branch, one commit per file,
+	// and returns the worktree path
+	commit := func(branch string, files ...string) string {
+		path := filepath.Join(m.WorktreeDir(), branch)
+		runGit(t, repo, "worktree", "add", "-q", "-b", branch, path, "origin/main")
+		for _, name := range files {
+			writeTestFile(t, filepath.Join(path, name), name+"\n")
+			runGit(t, path, "add", name)
+			runGit(t, path, "commit", "-q", "-m", "add "+name)
+		}
+		return path
+	}
+	commit("merged", "merged.txt")
+	commit("rebased", "rebased-1.txt", "rebased-2.txt")
+	commit("squashed", "squashed-1.txt", "squashed-2.txt")
+	reviewed := commit("reviewed", "reviewed.txt")
+	commit("unmerged", "unmerged.txt")
+
+	// A squash-merged branch without a worktree is not inspected
+	runGit(t, repo, "branch", "stale", "squashed")
+
+	// Land the branches upstream the ways the forge buttons do
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "unrelated")
+	runGit(t, upstream, "pull", "-q", "--no-rebase", "--no-edit", repo, "merged")
+	runGit(t, upstream, "fetch", "-q", repo, "rebased")
+	runGit(t, upstream, "cherry-pick", "main..FETCH_HEAD")
+	runGit(t, upstream, "fetch", "-q", repo, "squashed")
+	runGit(t, upstream, "merge", "-q", "--squash", "FETCH_HEAD")
+	runGit(t, upstream, "commit", "-q", "-m", "squashed (#2)")
+	runGit(t, repo, "fetch", "-q")
+
+	// Only the forge knows of a pull request whose changes were reworked
+	// when it landed
+	head := strings.TrimSpace(runGit(t, reviewed, "rev-parse", "HEAD"))
+	f.merged["reviewed"] = &forge.PullRequest{Number: 3, HeadSHA: head}
+
+	merged, err := m.GetMergedBranches(context.Background())
+	if err != nil {
+		t.Fatalf("GetMergedBranches() unexpected error: %v", err)
+	}
+
+	type summary struct {
+		Branch      string
+		Reason      worktree.MergeReason
+		PullRequest int
+	}
+	var got []summary
+	for _, mb := range merged {
+		got = append(got, summary{Branch: mb.Branch, Reason: mb.Reason, PullRequest: mb.PullRequest})
+	}
+	slices.SortFunc(got, func(a, b summary) int {
+		return strings.Compare(a.Branch, b.Branch)
+	})
+	want := []summary{
+		{Branch: "merged", Reason: worktree.MergeReasonMerged},
+		{Branch: "rebased", Reason: worktree.MergeReasonRebased},
+		{Branch: "reviewed", Reason: worktree.MergeReasonPullRequest, PullRequest: 3},
+		{Branch: "squashed", Reason: worktree.MergeReasonSquashed},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetMergedBranches() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerPruneMetadata(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.Fetch = config.FetchNever
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	create := func(name string, opts worktree.CreateOptions) string {
+		t.Helper()
+		if _, err := m.Create(ctx, name, opts); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", name, err)
+		}
+		return filepath.Join(m.WorktreeDir(), name)
+	}
+	load := func() map[string]*worktree.Metadata {
+		t.Helper()
+		store, err := m.Metadata(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		return all
+	}
+
+	// A worktree created again after 'git worktree remove' starts afresh
+	feature := create("feature", worktree.CreateOptions{From: "main"})
+	if err := m.UpdateMetadata(ctx, "feature", func(md *worktree.Metadata) {
+		md.Description = "Rework login flow"
+	}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+	runGit(t, repo, "worktree", "remove", feature)
+	create("feature", worktree.CreateOptions{})
+	if md := load()["feature"]; md == nil || md.Description != "" || md.CreatedAt.IsZero() {
+		t.Errorf("metadata of recreated worktree = %+v, want a fresh entry", md)
+	}
+
+	gone := create("gone", worktree.CreateOptions{From: "main"})
+	create("v1", worktree.CreateOptions{From: "main", Detach: true})
+	if err := m.MarkAccessed(ctx, "main"); err != nil {
+		t.Fatalf("MarkAccessed() unexpected error: %v", err)
+	}
+	runGit(t, repo, "worktree", "remove", gone)
+
+	pruned, err := m.PruneMetadata(ctx)
+	if err != nil {
+		t.Fatalf("PruneMetadata() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"gone"}, pruned); diff != "" {
+		t.Errorf("PruneMetadata() mismatch (-want +got):\n%s", diff)
+	}
+	var kept []string
+	for branch := range load() {
+		kept = append(kept, branch)
+	}
+	slices.Sort(kept)
+	if diff := cmp.Diff([]string{"feature", "main", "v1"}, kept); diff != "" {
+		t.Errorf("kept metadata mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerEnv(t *testing.T) {
+	for name, tt := range map[string]struct {
+		branch   string
+		assign   bool
+		expected map[string]string
+		stored   *worktree.Metadata
+	}{
+		"recorded": {
+			branch:   "feature",
+			expected: map[string]string{"GIWO_INDEX": "2", "GIWO_PORT": "10010", "GIWO_PORT_COUNT": "2"},
+			stored:   &worktree.Metadata{Index: 2, Ports: worktree.PortBlock{Start: 10010, Count: 2}},
+		},
+		"unrecorded stays unassigned": {
+			branch:   "fresh",
+			expected: map[string]string{},
+		},
+		"unrecorded with assign": {
+			branch:   "fresh",
+			assign:   true,
+			expected: map[string]string{"GIWO_INDEX": "1", "GIWO_PORT": "10000", "GIWO_PORT_COUNT": "10"},
+			stored:   &worktree.Metadata{Index: 1, Ports: worktree.PortBlock{Start: 10000, Count: 10}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := newFakeManager(t, worktreetest.NewFakeRunner())
+			ctx := context.Background()
+			if err := m.UpdateMetadata(ctx, "feature", func(md *worktree.Metadata) {
+				md.Index = 2
+				md.Ports = worktree.PortBlock{Start: 10010, Count: 2}
+			}); err != nil {
+				t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+			}
+
+			wt := &worktree.Worktree{Path: filepath.Join(fakeRepoRoot, ".worktree", tt.branch), Branch: tt.branch}
+			vars, err := m.Env(ctx, wt, tt.branch, tt.assign)
+			if err != nil {
+				t.Fatalf("Env() unexpected error: %v", err)
+			}
+			got := make(map[string]string)
+			for _, v := range vars {
+				if v.Name == "GIWO_INDEX" || strings.HasPrefix(v.Name, "GIWO_PORT") {
+					got[v.Name] = v.Value
+				}
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("Env() mismatch (-want +got):\n%s", diff)
+			}
+
+			store, err := m.Metadata(ctx)
+			if err != nil {
+				t.Fatalf("Metadata() unexpected error: %v", err)
+			}
+			md, err := store.Get(tt.branch)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.stored, md); diff != "" {
+				t.Errorf("metadata after Env() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+// HookEvent identifies a worktree lifecycle event.
+type HookEvent string
+
+// Hook event constants.
+const (
+	HookPostCreate HookEvent = "post-create"
+	HookPreRemove  HookEvent = "pre-remove"
+	HookPostRemove HookEvent = "post-remove"
+	HookPostSwitch HookEvent = "post-switch"
+)
+
+// HookContext describes the worktree a hook runs for.
+type HookContext struct {
+	Branch string
+	Path   string
+	Base   string
+	Ports  PortBlock
+}
+
+// RunHooks runs the configured hooks for event in order.
+// Hooks run inside the worktree, or in the repository root once the worktree
+// is gone. Their output is streamed to stderr so it never mixes with giwo's
+// own output. The first hook that fails stops the sequence.
+func (m *Manager) RunHooks(ctx context.Context, event HookEvent, hc HookContext) error {
+	hooks := m.hooksFor(event)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	dir := hc.Path
+	if event == HookPostRemove || dir == "" {
+		dir = m.repoRoot
+	}
+
+	for _, hook := range hooks {
+		fmt.Fprintf(os.Stderr, "🪝 Running %s hook: %s\n", event, hook.Run)
+		if err := m.runHook(ctx, event, hook, dir, hc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hooksFor returns the configured hooks for event.
+func (m *Manager) hooksFor(event HookEvent) []config.Hook {
+	switch event {
+	case HookPostCreate:
+		return m.cfg.Hooks.PostCreate
+	case HookPreRemove:
+		return m.cfg.Hooks.PreRemove
+	case HookPostRemove:
+		return m.cfg.Hooks.PostRemove
+	case HookPostSwitch:
+		return m.cfg.Hooks.PostSwitch
+	default:
+		return nil
+	}
+}
+
+// runHook runs a single hook in dir and converts failures into a HookError.
+func (m *Manager) runHook(ctx context.Context, event HookEvent, hook config.Hook, dir string, hc HookContext) error {
+	if timeout := m.cfg.Hooks.TimeoutFor(hook); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	cmd.Dir = dir
+	killProcessGroupOnCancel(cmd)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GIWO_HOOK="+string(event),
+		"GIWO_BRANCH="+hc.Branch,
+		"GIWO_PATH="+hc.Path,
+		"GIWO_BASE="+hc.Base,
+		"GIWO_REPO_ROOT="+m.repoRoot,
+	)
+	// Empty unless the worktree has ports, so that none leak in from the
+	// environment of another worktree
+	var port, portCount string
+	if !hc.Ports.IsZero() {
+		port, portCount = strconv.Itoa(hc.Ports.Start), strconv.Itoa(hc.Ports.Count)
+	}
+	cmd.Env = append(cmd.Env, "GIWO_PORT="+port, "GIWO_PORT_COUNT="+portCount)
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.NewHookError(string(event), hook.Run, -1, errors.ErrHookTimeout)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+		return errors.NewHookError(string(event), hook.Run, exitErr.ExitCode(), err)
+	}
+	return errors.NewHookError(string(event), hook.Run, -1, err)
+}
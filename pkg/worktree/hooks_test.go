@@ -0,0 +1,91 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+func TestRunHooks(t *testing.T) {
+	for name, tt := range map[string]struct {
+		hooks        []config.Hook
+		wantExitCode int
+		wantErr      error
+	}{
+		"exports environment": {
+			hooks: []config.Hook{
+				{Run: `printf '%s|%s|%s|%s|%s|%s|%s' "$GIWO_HOOK" "$GIWO_BRANCH" "$GIWO_BASE" "$GIWO_PATH" "$GIWO_REPO_ROOT" "$GIWO_PORT" "$GIWO_PORT_COUNT" > out.txt`},
+			},
+		},
+		"non-zero exit fails": {
+			hooks: []config.Hook{
+				{Run: "exit 3"},
+				{Run: "touch should-not-run"},
+			},
+			wantExitCode: 3,
+		},
+		"timeout fails": {
+			hooks: []config.Hook{
+				{Run: "sleep 5", Timeout: config.Duration(50 * time.Millisecond)},
+			},
+			wantExitCode: -1,
+			wantErr:      giwoerrors.ErrHookTimeout,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repoRoot := t.TempDir()
+			worktreePath := t.TempDir()
+
+			cfg := config.Default()
+			cfg.Hooks.PostCreate = tt.hooks
+			m := &Manager{repoRoot: repoRoot, cfg: cfg}
+
+			hc := HookContext{
+				Branch: "feature-auth",
+				Path:   worktreePath,
+				Base:   "main",
+				Ports:  PortBlock{Start: 10010, Count: 10},
+			}
+			err := m.RunHooks(context.Background(), HookPostCreate, hc)
+
+			if tt.wantExitCode == 0 {
+				if err != nil {
+					t.Fatalf("RunHooks() unexpected error: %v", err)
+				}
+				got, err := os.ReadFile(filepath.Join(worktreePath, "out.txt"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := "post-create|feature-auth|main|" + worktreePath + "|" + repoRoot + "|10010|10"
+				if diff := cmp.Diff(want, string(got)); diff != "" {
+					t.Errorf("hook environment mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+
+			var hookErr *giwoerrors.HookError
+			if !errors.As(err, &hookErr) {
+				t.Fatalf("RunHooks() error = %v, want *HookError", err)
+			}
+			if diff := cmp.Diff(tt.wantExitCode, hookErr.ExitCode); diff != "" {
+				t.Errorf("ExitCode mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunHooks() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(worktreePath, "should-not-run")); err == nil {
+				t.Error("hooks after a failing hook should not run")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// processesUsing returns the processes whose current directory is inside
+// path, formatted as "pid (command)". giwo itself and the shell that started
+// it are ignored since they leave the directory with the removal. Processes
+// that cannot be inspected are skipped.
+func processesUsing(path string) []string {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	self, parent := os.Getpid(), os.Getppid()
+
+	var users []string
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self || pid == parent {
+			continue
+		}
+
+		cwd, err := os.Readlink(filepath.Join("/proc", entry.Name(), "cwd"))
+		if err != nil || !isWithin(cwd, path) {
+			continue
+		}
+
+		comm, _ := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		users = append(users, fmt.Sprintf("%d (%s)", pid, strings.TrimSpace(string(comm))))
+	}
+	return users
+}
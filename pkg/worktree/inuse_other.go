@@ -0,0 +1,8 @@
+//go:build !linux
+
+package worktree
+
+// processesUsing is not implemented on this platform and reports no processes.
+func processesUsing(path string) []string {
+	return nil
+}
@@ -0,0 +1,46 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Lock locks the worktree of branch with 'git worktree lock', so that it is
+// neither pruned nor moved, and giwo skips it when removing and cleaning.
+// reason is recorded with the lock and may be empty.
+func (m *Manager) Lock(ctx context.Context, branch, reason string) error {
+	wt, err := m.FindWorktree(ctx, branch)
+	if err != nil {
+		return err
+	}
+	if wt.Locked {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeLocked, branch)
+	}
+
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	if err := m.runGitCommand(ctx, append(args, wt.Path)...); err != nil {
+		return fmt.Errorf("failed to lock worktree: %w", err)
+	}
+	return nil
+}
+
+// Unlock removes the lock from the worktree of branch.
+func (m *Manager) Unlock(ctx context.Context, branch string) error {
+	wt, err := m.FindWorktree(ctx, branch)
+	if err != nil {
+		return err
+	}
+	if !wt.Locked {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeNotLocked, branch)
+	}
+
+	if err := m.runGitCommand(ctx, "worktree", "unlock", wt.Path); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %w", err)
+	}
+	return nil
+}
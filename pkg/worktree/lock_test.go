@@ -0,0 +1,82 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/knwoop/giwo/pkg/worktree/worktreetest"
+)
+
+func TestManagerLock(t *testing.T) {
+	path := fakeRepoRoot + "/.worktree/feature"
+	locked := porcelainEntry(path, "2222222222222222222222222222222222222222", "branch refs/heads/feature", "locked on a USB drive")
+
+	for name, tt := range map[string]struct {
+		list    string
+		call    func(m *worktree.Manager) error
+		script  func(r *worktreetest.FakeRunner)
+		wantErr error
+	}{
+		"lock with reason": {
+			list: porcelainList(fakeRepoRoot, path),
+			call: func(m *worktree.Manager) error { return m.Lock(context.Background(), "feature", "on a USB drive") },
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("worktree", "lock", "--reason", "on a USB drive", path).Return("")
+			},
+		},
+		"lock without reason": {
+			list: porcelainList(fakeRepoRoot, path),
+			call: func(m *worktree.Manager) error { return m.Lock(context.Background(), "feature", "") },
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("worktree", "lock", path).Return("")
+			},
+		},
+		"already locked": {
+			list:    porcelainList(fakeRepoRoot) + locked,
+			call:    func(m *worktree.Manager) error { return m.Lock(context.Background(), "feature", "") },
+			wantErr: giwoerrors.ErrWorktreeLocked,
+		},
+		"unlock": {
+			list: porcelainList(fakeRepoRoot) + locked,
+			call: func(m *worktree.Manager) error { return m.Unlock(context.Background(), "feature") },
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("worktree", "unlock", path).Return("")
+			},
+		},
+		"unlock when not locked": {
+			list:    porcelainList(fakeRepoRoot, path),
+			call:    func(m *worktree.Manager) error { return m.Unlock(context.Background(), "feature") },
+			wantErr: giwoerrors.ErrWorktreeNotLocked,
+		},
+		"missing worktree": {
+			list:    porcelainList(fakeRepoRoot),
+			call:    func(m *worktree.Manager) error { return m.Lock(context.Background(), "feature", "") },
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			runner.On("worktree", "list", "--porcelain", "-z").Return(tt.list)
+			if tt.script != nil {
+				tt.script(runner)
+			}
+
+			err := tt.call(m)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -6,39 +6,113 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/forge"
 )
 
 // Manager handles Git worktree operations.
 type Manager struct {
 	repoRoot    string
+	workDir     string
 	worktreeDir string
+	cfg         *config.Config
+	runner      GitRunner
+	metadata    *MetadataStore
+
+	forge         forge.Forge
+	forgeErr      error
+	forgeResolved bool
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithConfig makes the Manager use cfg instead of loading the configuration
+// from the repository and user config files.
+func WithConfig(cfg *config.Config) Option {
+	return func(m *Manager) {
+		m.cfg = cfg
+	}
+}
+
+// WithGitRunner makes the Manager execute git through r instead of running
+// the git executable directly.
+func WithGitRunner(r GitRunner) Option {
+	return func(m *Manager) {
+		m.runner = r
+	}
+}
+
+// WithRepoRoot makes the Manager operate on the repository at dir instead of
+// the repository containing the current directory.
+func WithRepoRoot(dir string) Option {
+	return func(m *Manager) {
+		m.repoRoot = dir
+	}
+}
+
+// WithForge makes the Manager use f instead of detecting the forge from the
+// default remote.
+func WithForge(f forge.Forge) Option {
+	return func(m *Manager) {
+		m.forge = f
+		m.forgeResolved = true
+	}
 }
 
 // New creates a new Manager instance.
-// It returns an error if the current directory is not in a Git repository.
-func New() (*Manager, error) {
-	repoRoot, err := getGitRoot()
+// It returns an error if the current directory is not in a Git repository
+// or if the configuration cannot be loaded.
+func New(opts ...Option) (*Manager, error) {
+	m := &Manager{
+		runner: ExecRunner{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	ctx := context.Background()
+	repoRoot, err := m.git(ctx, m.repoRoot, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNotGitRepository, err)
+	}
+	m.repoRoot = repoRoot
+	m.workDir = repoRoot
+
+	// Inside a linked worktree, operate on the main worktree so that paths
+	// and configuration are the same wherever giwo is run from. The current
+	// branch and worktree are still those of workDir.
+	commonDir, err := m.GitCommonDir(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrNotGitRepository, err)
 	}
+	if filepath.Base(commonDir) == ".git" {
+		repoRoot = filepath.Dir(commonDir)
+		m.repoRoot = repoRoot
+	}
 
-	worktreeDir := filepath.Join(repoRoot, ".worktree")
+	if m.cfg == nil {
+		m.cfg, err = config.Load(repoRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.worktreeDir = m.cfg.ResolveWorktreeDir(repoRoot)
 
-	return &Manager{
-		repoRoot:    repoRoot,
-		worktreeDir: worktreeDir,
-	}, nil
+	return m, nil
 }
 
-// WorktreeDir returns the directory where worktrees are stored.
+// WorktreeDir returns the directory where worktrees are stored, unless a
+// worktree_path template places them elsewhere; see WorktreePath.
 func (m *Manager) WorktreeDir() string {
 	return m.worktreeDir
 }
@@ -48,174 +122,818 @@ func (m *Manager) RepoRoot() string {
 	return m.repoRoot
 }
 
-// List returns all worktrees with their current status.
-func (m *Manager) List(ctx context.Context) ([]*Worktree, error) {
-	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+// Config returns the configuration used by the Manager.
+func (m *Manager) Config() *config.Config {
+	return m.cfg
+}
+
+// Forge returns the forge hosting the default remote.
+// The forge is detected from the remote URL on first use, honoring the forge
+// settings of the configuration.
+func (m *Manager) Forge() (forge.Forge, error) {
+	if m.forgeResolved {
+		return m.forge, m.forgeErr
+	}
+	m.forgeResolved = true
+
+	remoteURL, err := m.RemoteURL()
 	if err != nil {
-		return nil, errors.NewGitError("worktree list", []string{"--porcelain"}, err)
+		m.forgeErr = err
+		return nil, err
+	}
+
+	m.forge, m.forgeErr = forge.New(remoteURL, forge.Options{
+		Kind:   forge.Kind(m.cfg.Forge.Type),
+		APIURL: m.cfg.Forge.APIURL,
+		Git:    m.gitOutput,
+		Remote: m.cfg.DefaultRemote,
+	})
+	return m.forge, m.forgeErr
+}
+
+// Worktrees returns all worktrees with only what 'git worktree list' reports
+// set: path, branch, HEAD and the bare, detached, locked and prunable state.
+// Unlike List it does not inspect each worktree, which makes it suitable for
+// shell completion.
+func (m *Manager) Worktrees(ctx context.Context) ([]*Worktree, error) {
+	// -z keeps paths containing newlines intact but needs git 2.36
+	output, err := m.runner.Run(ctx, GitCommand{Dir: m.repoRoot, Args: []string{"worktree", "list", "--porcelain", "-z"}})
+	if err != nil && ctx.Err() == nil {
+		output, err = m.runner.Run(ctx, GitCommand{Dir: m.repoRoot, Args: []string{"worktree", "list", "--porcelain"}})
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	worktrees, err := m.parseWorktreeList(string(output))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse worktree list: %w", err)
 	}
+	return worktrees, nil
+}
 
-	// Enrich each worktree with additional information
+// List returns all worktrees with their current status.
+// Worktrees are inspected concurrently, at most Config.Jobs at a time. A
+// worktree whose status cannot be read is still returned with Error set.
+func (m *Manager) List(ctx context.Context) ([]*Worktree, error) {
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := m.Metadata(ctx)
+	if err == nil {
+		var all map[string]*Metadata
+		if all, err = store.Load(); err == nil {
+			for _, wt := range worktrees {
+				wt.Metadata = all[wt.Branch]
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to load worktree metadata: %v\n", err)
+	}
+
+	if err := m.enrichWorktrees(ctx, worktrees); err != nil {
+		return nil, err
+	}
+
+	return worktrees, nil
+}
+
+// enrichWorktrees enriches worktrees concurrently.
+// It returns an error only if ctx is cancelled.
+func (m *Manager) enrichWorktrees(ctx context.Context, worktrees []*Worktree) error {
+	jobs := m.cfg.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
 	for _, wt := range worktrees {
-		if err := m.enrichWorktree(ctx, wt); err != nil {
-			// Log warning but continue with other worktrees
+		// There is no working tree to inspect
+		if wt.Bare || wt.Prunable {
 			continue
 		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := m.enrichWorktree(ctx, wt); err != nil {
+				wt.Error = err.Error()
+			}
+		}()
 	}
+	wg.Wait()
 
-	return worktrees, nil
+	return ctx.Err()
 }
 
-// Create creates a new worktree and branch.
-func (m *Manager) Create(ctx context.Context, branchName, baseBranch string, force bool) error {
-	worktreePath := filepath.Join(m.worktreeDir, branchName)
+// BranchSource says where the branch of a new worktree came from.
+type BranchSource string
+
+// Branch source constants.
+const (
+	// BranchSourceNew means a new branch was created from the base branch
+	// or the requested revision.
+	BranchSourceNew BranchSource = "new"
+	// BranchSourceLocal means an existing local branch was checked out.
+	BranchSourceLocal BranchSource = "local"
+	// BranchSourceRemote means a local branch was created to track an
+	// existing branch of the default remote.
+	BranchSourceRemote BranchSource = "remote"
+	// BranchSourceDetached means the worktree has no branch.
+	BranchSourceDetached BranchSource = "detached"
+)
 
-	if !force {
+// CreateOptions controls how Create picks the branch of a new worktree.
+type CreateOptions struct {
+	// Base is the branch new branches start from. Empty means default_base,
+	// then main.
+	Base string
+
+	// From is the commit-ish a new branch starts from, such as a tag, a SHA
+	// or HEAD~3. It takes precedence over Base and implies New.
+	From string
+
+	// Detach checks out From, or the name passed to Create if From is
+	// empty, with a detached HEAD instead of on a branch.
+	Detach bool
+
+	// Force creates the worktree even if its directory exists.
+	Force bool
+
+	// New always creates a new branch instead of checking out an existing
+	// local or remote branch of the same name.
+	New bool
+
+	// Fetch is the fetch policy applied before creating the worktree.
+	// Empty means the configured policy.
+	Fetch config.FetchPolicy
+}
+
+// Create creates a worktree for branchName. An existing local branch is
+// checked out and an existing branch of the default remote is tracked;
+// otherwise, or with opts.New, a new branch is created from opts.From or
+// opts.Base. A branch checked out in another worktree yields a
+// CheckedOutError. With opts.Detach, branchName only names the worktree.
+func (m *Manager) Create(ctx context.Context, branchName string, opts CreateOptions) (BranchSource, error) {
+	worktreePath, err := m.WorktreePath(branchName)
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.Detach {
+		worktrees, err := m.Worktrees(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, wt := range worktrees {
+			if wt.Branch == branchName {
+				return "", errors.NewCheckedOutError(branchName, wt.Path)
+			}
+		}
+	}
+
+	if !opts.Force {
 		if _, err := os.Stat(worktreePath); err == nil {
-			return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+			return "", fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
 		}
 	}
 
-	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create worktree directory: %w", err)
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
 	}
 
+	baseBranch := opts.Base
+	if baseBranch == "" {
+		baseBranch = m.cfg.DefaultBase
+	}
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
 
-	// Fetch the latest changes
-	if err := m.runGitCommand(ctx, "fetch", "--prune"); err != nil {
-		return fmt.Errorf("failed to fetch: %w", err)
+	from := opts.From
+	if opts.Detach && from == "" {
+		from = branchName
 	}
 
-	// Create the worktree
-	args := []string{"worktree", "add", "-b", branchName, worktreePath, fmt.Sprintf("origin/%s", baseBranch)}
-	if err := m.runGitCommand(ctx, args...); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	// Fetch the latest changes, falling back to the local base branch when
+	// the remote is unreachable since its remote-tracking branch may be stale
+	startPoint := m.remoteRef(baseBranch)
+	fetchBase := baseBranch
+	if from != "" {
+		fetchBase = ""
+	}
+	if err := m.fetchBase(ctx, opts.Fetch, fetchBase); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		fmt.Printf("⚠️  Warning: failed to fetch from %s, continuing offline: %v\n", m.cfg.DefaultRemote, err)
+		if from == "" && m.BranchExists(ctx, baseBranch) {
+			startPoint = baseBranch
+		}
+	}
+	// A base branch that was never pushed, such as the branch of the linked
+	// worktree giwo runs in, starts from its local tip
+	if from == "" && startPoint != baseBranch &&
+		m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+startPoint) != nil &&
+		m.BranchExists(ctx, baseBranch) {
+		startPoint = baseBranch
+	}
+	if from != "" {
+		if err := m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", from+"^{commit}"); err != nil {
+			return "", fmt.Errorf("%w: %s", errors.ErrInvalidRevision, from)
+		}
 	}
 
-	// Copy configuration files
-	if err := m.copyConfigFiles(worktreePath); err != nil {
-		// This is not a fatal error, just log a warning
-		fmt.Printf("⚠️  Warning: failed to copy config files: %v\n", err)
+	remoteBranch := m.remoteRef(branchName)
+	source := BranchSourceNew
+	args := []string{"worktree", "add", "-b", branchName, worktreePath, startPoint}
+	switch {
+	case opts.Detach:
+		source = BranchSourceDetached
+		args = []string{"worktree", "add", "--detach", worktreePath, from}
+	case opts.New || from != "":
+		if m.BranchExists(ctx, branchName) {
+			return "", fmt.Errorf("%w: %s", errors.ErrBranchExists, branchName)
+		}
+		if from != "" {
+			args = []string{"worktree", "add", "-b", branchName, worktreePath, from}
+		}
+	case m.BranchExists(ctx, branchName):
+		source = BranchSourceLocal
+		args = []string{"worktree", "add", worktreePath, branchName}
+	case m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteBranch) == nil:
+		source = BranchSourceRemote
+		args = []string{"worktree", "add", "--track", "-b", branchName, worktreePath, remoteBranch}
 	}
 
-	return nil
+	newBranch := source == BranchSourceNew || source == BranchSourceRemote
+	undo, err := m.addWorktree(ctx, branchName, worktreePath, newBranch, args...)
+	if err != nil {
+		return "", err
+	}
+
+	// Record what the branch started from; the base of an existing branch
+	// is unknown and detached worktrees have no branch to record it for
+	switch {
+	case source != BranchSourceNew:
+		baseBranch = ""
+	case from != "":
+		baseBranch = from
+	}
+
+	// Detached worktrees are recorded by name, which keeps their index
+	m.revertMetadata(ctx, &undo, branchName)
+	m.recordCreation(ctx, branchName, func(md *Metadata) {
+		md.Base = baseBranch
+	})
+
+	if err := m.setupWorktree(ctx, branchName, worktreePath, baseBranch); err != nil {
+		undo.run(ctx)
+		return "", err
+	}
+	return source, nil
+}
+
+// PullRequestBranch returns the local branch name used for pull request number.
+func PullRequestBranch(number int) string {
+	return fmt.Sprintf("pr-%d", number)
 }
 
-// Remove removes a worktree and optionally its branch.
-func (m *Manager) Remove(ctx context.Context, branchName string, force, keepBranch bool) error {
-	worktreePath := filepath.Join(m.worktreeDir, branchName)
+// pullRefPrefix is the namespace pull request heads are fetched into. Unlike
+// refs/remotes it is not pruned by 'git fetch --prune' and cannot clash with
+// branches on the remote.
+const pullRefPrefix = "refs/giwo/pull/"
+
+// pullRequestRef returns the ref holding the fetched head of pull request
+// number.
+func pullRequestRef(number int) string {
+	return fmt.Sprintf("%s%d", pullRefPrefix, number)
+}
+
+// pullRequestNumber returns the pull request number of a branch named by
+// PullRequestBranch.
+func pullRequestNumber(branch string) (int, bool) {
+	digits, ok := strings.CutPrefix(branch, "pr-")
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.Atoi(digits)
+	return number, err == nil && number > 0 && PullRequestBranch(number) == branch
+}
+
+// PullRequestSource describes where to fetch the head of a pull request from.
+type PullRequestSource struct {
+	Number int
+
+	// Remote is a remote name or URL. Empty means the default remote.
+	Remote string
+
+	// Ref is the ref to fetch. Empty means refs/pull/<number>/head.
+	Ref string
+
+	// Base is the target branch of the pull request, passed on to hooks.
+	Base string
+}
+
+// CreateFromPullRequest creates a worktree for a pull request.
+// It fetches the pull request head, which also covers pull requests opened
+// from forks, into a pr-<number> branch that tracks it.
+func (m *Manager) CreateFromPullRequest(ctx context.Context, src PullRequestSource, force bool) error {
+	number := src.Number
+	if src.Remote == "" {
+		src.Remote = m.cfg.DefaultRemote
+	}
+	if src.Ref == "" {
+		src.Ref = fmt.Sprintf("refs/pull/%d/head", number)
+	}
+
+	branchName := PullRequestBranch(number)
+	worktreePath, err := m.WorktreePath(branchName)
+	if err != nil {
+		return err
+	}
 
 	if !force {
+		if _, err := os.Stat(worktreePath); err == nil {
+			return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	// Fetch the pull request head into a ref of giwo's own
+	headRef := pullRequestRef(number)
+	refspec := fmt.Sprintf("+%s:%s", src.Ref, headRef)
+	if err := m.runGitCommand(ctx, "fetch", src.Remote, refspec); err != nil {
+		return fmt.Errorf("%w: #%d: %v", errors.ErrPullRequestNotFound, number, err)
+	}
+
+	// Reuse the local branch from an earlier review, brought up to date with
+	// the pull request, otherwise create it
+	newBranch := !m.BranchExists(ctx, branchName)
+	args := []string{"worktree", "add", worktreePath, branchName}
+	if newBranch {
+		args = []string{"worktree", "add", "-b", branchName, worktreePath, headRef}
+	} else if err := m.fastForwardBranch(ctx, branchName, headRef); err != nil {
+		return fmt.Errorf("%w: #%d", err, number)
+	}
+	undo, err := m.addWorktree(ctx, branchName, worktreePath, newBranch, args...)
+	if err != nil {
+		return err
+	}
+
+	// Track the pull request head so that 'git pull' picks up new commits
+	if err := m.runGitCommand(ctx, "config", fmt.Sprintf("branch.%s.remote", branchName), src.Remote); err != nil {
+		undo.run(ctx)
+		return fmt.Errorf("failed to set upstream: %w", err)
+	}
+	if err := m.runGitCommand(ctx, "config", fmt.Sprintf("branch.%s.merge", branchName), src.Ref); err != nil {
+		undo.run(ctx)
+		return fmt.Errorf("failed to set upstream: %w", err)
+	}
+
+	m.revertMetadata(ctx, &undo, branchName)
+	m.recordCreation(ctx, branchName, func(md *Metadata) {
+		md.Base = src.Base
+		md.PullRequest = number
+	})
+
+	if err := m.setupWorktree(ctx, branchName, worktreePath, src.Base); err != nil {
+		undo.run(ctx)
+		return err
+	}
+	return nil
+}
+
+// setupWorktree copies configuration files into a newly added worktree,
+// renders its templates, writes its .envrc and bootstraps its dependencies
+// if configured, and runs the post-create hooks. Callers roll the
+// worktree back when it fails, including when ctx is canceled.
+func (m *Manager) setupWorktree(ctx context.Context, branchName, worktreePath, baseBranch string) error {
+	if err := m.copyConfigFiles(ctx, worktreePath); err != nil {
+		return fmt.Errorf("failed to copy config files: %w", err)
+	}
+	// Templates would render without the index and ports they rely on
+	data, err := m.templateData(ctx, branchName, worktreePath, baseBranch)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: %v, skipping templates\n", err)
+	} else if err := m.renderTemplates(ctx, worktreePath, data); err != nil {
+		return err
+	}
+	if m.cfg.Env.Envrc {
+		if err := m.writeEnvrc(ctx, data); err != nil {
+			fmt.Printf("⚠️  Warning: failed to write %s: %v\n", EnvrcFile, err)
+		}
+	}
+	if m.cfg.Bootstrap.Enabled {
+		m.bootstrapNew(ctx, worktreePath)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	hc := HookContext{
+		Branch: branchName,
+		Path:   worktreePath,
+		Base:   baseBranch,
+		Ports:  PortBlock{Start: data.Port, Count: len(data.Ports)},
+	}
+	return m.RunHooks(ctx, HookPostCreate, hc)
+}
+
+// RemoveOptions controls how Remove treats risky worktrees.
+type RemoveOptions struct {
+	// KeepBranch keeps the local branch after removing the worktree.
+	KeepBranch bool
+
+	// Yes skips the confirmation prompt.
+	Yes bool
+
+	// Accept lists the risks the caller accepts. Remove refuses with a
+	// RiskError if Preflight finds any other risk.
+	Accept []Risk
+
+	// NoArchive skips archiving the worktree, so that the removal cannot be
+	// undone with Restore.
+	NoArchive bool
+}
+
+// Remove removes a worktree and, unless opts.KeepBranch is set, its branch.
+// It never forces the removal beyond the risks listed in opts.Accept.
+// Unless opts.NoArchive is set, the worktree content and branch tip are
+// archived first so that Restore can bring them back, and archives older
+// than the retention period are purged.
+func (m *Manager) Remove(ctx context.Context, branchName string, opts RemoveOptions) error {
+	wt, err := m.FindWorktree(ctx, branchName)
+	if err != nil {
+		return err
+	}
+	worktreePath := wt.Path
+
+	findings, err := m.preflight(ctx, wt, opts.KeepBranch)
+	if err != nil {
+		return err
+	}
+
+	var risks, details []string
+	for _, f := range findings {
+		if !slices.Contains(opts.Accept, f.Risk) {
+			risks = append(risks, string(f.Risk))
+			details = append(details, f.Detail)
+		}
+	}
+	if len(risks) > 0 {
+		return errors.NewRiskError(branchName, risks, details)
+	}
+
+	if !opts.Yes {
+		for _, f := range findings {
+			fmt.Printf("⚠️  %s: %s\n", f.Risk, f.Detail)
+		}
 		if !m.confirmRemoval(branchName, worktreePath) {
 			return errors.ErrOperationCancelled
 		}
 	}
 
-	// Remove the worktree
-	if err := m.runGitCommand(ctx, "worktree", "remove", worktreePath); err != nil {
-		// Try with force flag
-		if err := m.runGitCommand(ctx, "worktree", "remove", "--force", worktreePath); err != nil {
-			return fmt.Errorf("failed to remove worktree: %w", err)
+	hc := HookContext{Branch: branchName, Path: worktreePath, Ports: m.recordedPorts(ctx, branchName)}
+	if err := m.RunHooks(ctx, HookPreRemove, hc); err != nil {
+		return err
+	}
+
+	var archive *Archive
+	if !opts.NoArchive {
+		if archive, err = m.archiveWorktree(ctx, branchName, wt); err != nil {
+			return fmt.Errorf("failed to archive worktree: %w", err)
+		}
+	}
+
+	// Git refuses to remove dirty worktrees without one --force and locked
+	// worktrees without two, so only pass what the accepted risks require.
+	args := []string{"worktree", "remove"}
+	if slices.Contains(opts.Accept, RiskUncommitted) || slices.Contains(opts.Accept, RiskUntracked) {
+		args = append(args, "--force")
+	}
+	if slices.Contains(opts.Accept, RiskLocked) {
+		args = append(args, "--force", "--force")
+	}
+	if err := m.runGitCommand(ctx, append(args, worktreePath)...); err != nil {
+		// The worktree is still there, so its archive would only restore a copy
+		if archive != nil {
+			if err := m.dropArchives(context.WithoutCancel(ctx), archive); err != nil {
+				fmt.Printf("⚠️  Warning: failed to drop archive: %v\n", err)
+			}
 		}
+		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
+	removeEmptyDirs(filepath.Dir(worktreePath), m.worktreeBase())
 
-	// Remove the branch if requested
-	if !keepBranch {
+	// Preflight made sure the branch is merged or its loss accepted
+	if !opts.KeepBranch && !wt.Detached {
 		if err := m.runGitCommand(ctx, "branch", "-D", branchName); err != nil {
 			fmt.Printf("⚠️  Warning: failed to delete branch '%s': %v\n", branchName, err)
 		}
+		if number, ok := pullRequestNumber(branchName); ok {
+			_ = m.runGitCommand(ctx, "update-ref", "-d", pullRequestRef(number))
+		}
+	}
+
+	if store, err := m.Metadata(ctx); err == nil {
+		if err := store.Delete(branchName); err != nil {
+			fmt.Printf("⚠️  Warning: failed to delete worktree metadata: %v\n", err)
+		}
+	}
+
+	if !opts.NoArchive {
+		m.purgeExpiredArchives(ctx)
+	}
+
+	if err := m.RunHooks(ctx, HookPostRemove, hc); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// GetMergedBranches returns a list of branches that have been merged.
-func (m *Manager) GetMergedBranches(ctx context.Context) ([]string, error) {
-	// Try main first, then master
-	for _, mainBranch := range []string{"main", "master"} {
-		cmd := exec.CommandContext(ctx, "git", "branch", "--merged", fmt.Sprintf("origin/%s", mainBranch))
-		cmd.Dir = m.repoRoot
-		output, err := cmd.Output()
-		if err != nil {
+// Prune removes administrative files of worktrees whose directory no longer
+// exists and returns git's report of what was pruned. See PruneMetadata for
+// the metadata giwo keeps of them.
+func (m *Manager) Prune(ctx context.Context) (string, error) {
+	output, err := m.gitOutput(ctx, "worktree", "prune", "-v")
+	if err != nil {
+		return "", fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+	return output, nil
+}
+
+// GetMergedBranches returns the branches of linked worktrees whose work has
+// landed in the base branch.
+// Besides regular merges it detects branches merged with GitHub's squash or
+// rebase buttons by comparing patch IDs against the base branch. When the forge
+// has credentials, branches with a merged pull request are reported as well.
+// Branches without a worktree are not inspected, since clean leaves them alone.
+func (m *Manager) GetMergedBranches(ctx context.Context) ([]MergedBranch, error) {
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	hasWorktree := make(map[string]bool)
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Branch == "" || m.cfg.IsProtected(wt.Branch) {
 			continue
 		}
+		branches = append(branches, wt.Branch)
+		hasWorktree[wt.Branch] = true
+	}
+	if len(branches) == 0 {
+		return nil, nil
+	}
+
+	baseRef, err := m.resolveBaseRef(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		return m.parseBranchList(string(output)), nil
+	output, err := m.gitOutput(ctx, "branch", "--merged", baseRef)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("failed to determine merged branches: no main/master branch found")
+	var merged []MergedBranch
+	isMerged := make(map[string]bool)
+	for _, branch := range m.parseBranchList(output) {
+		if !hasWorktree[branch] {
+			continue
+		}
+		merged = append(merged, MergedBranch{Branch: branch, Reason: MergeReasonMerged, Base: baseRef})
+		isMerged[branch] = true
+	}
+
+	f, _ := m.Forge()
+	if f != nil && !f.Authenticated() {
+		f = nil
+	}
+
+	for _, branch := range branches {
+		if isMerged[branch] {
+			continue
+		}
+
+		if mb, ok := m.detectMerge(ctx, branch, baseRef, f); ok {
+			merged = append(merged, mb)
+		}
+	}
+
+	return merged, nil
 }
 
-// GetRepoInfo extracts GitHub repository information from Git remote.
-func (m *Manager) GetRepoInfo() (owner, repo string, err error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+// detectMerge checks whether branch was rebase- or squash-merged into baseRef
+// or, if f is not nil, has a merged pull request.
+func (m *Manager) detectMerge(ctx context.Context, branch, baseRef string, f forge.Forge) (MergedBranch, bool) {
+	mb := MergedBranch{Branch: branch, Base: baseRef}
+
+	// Rebase merge: every commit of the branch has an equivalent patch in base
+	if output, err := m.gitOutput(ctx, "cherry", baseRef, branch); err == nil && allPatchesUpstream(output) {
+		mb.Reason = MergeReasonRebased
+		return mb, true
+	}
+
+	// Squash merge: the combined diff of the branch has an equivalent patch in base
+	if squashed, err := m.isSquashMerged(ctx, branch, baseRef); err == nil && squashed {
+		mb.Reason = MergeReasonSquashed
+		return mb, true
+	}
+
+	if f == nil {
+		return mb, false
+	}
+
+	pr, err := f.MergedPullRequest(ctx, branch)
+	if err != nil || pr == nil {
+		return mb, false
+	}
+
+	// Only trust the pull request if it contains the local branch tip, so
+	// that new work pushed after the merge is not discarded.
+	if err := m.runGitCommand(ctx, "merge-base", "--is-ancestor", branch, pr.HeadSHA); err != nil {
+		return mb, false
+	}
+
+	mb.Reason = MergeReasonPullRequest
+	mb.PullRequest = pr.Number
+	return mb, true
+}
+
+// isSquashMerged builds a temporary commit holding the combined changes of
+// branch since it forked from baseRef and checks whether base contains an
+// equivalent patch.
+func (m *Manager) isSquashMerged(ctx context.Context, branch, baseRef string) (bool, error) {
+	mergeBase, err := m.gitOutput(ctx, "merge-base", baseRef, branch)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get remote URL: %w", err)
+		return false, err
 	}
 
-	remoteURL := strings.TrimSpace(string(output))
-	owner, repo = parseGitHubURL(remoteURL)
+	tree, err := m.gitOutput(ctx, "rev-parse", branch+"^{tree}")
+	if err != nil {
+		return false, err
+	}
+
+	squash, err := m.gitOutput(ctx, "-c", "user.name=giwo", "-c", "user.email=giwo@localhost",
+		"commit-tree", tree, "-p", mergeBase, "-m", "giwo squash check")
+	if err != nil {
+		return false, err
+	}
 
-	if owner == "" || repo == "" {
-		return "", "", fmt.Errorf("failed to parse GitHub repository info from: %s", remoteURL)
+	output, err := m.gitOutput(ctx, "cherry", baseRef, squash)
+	if err != nil {
+		return false, err
 	}
 
-	return owner, repo, nil
+	return allPatchesUpstream(output), nil
 }
 
-// parseWorktreeList parses the output of 'git worktree list --porcelain'.
-func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
-	var worktrees []*Worktree
-	lines := strings.Split(output, "\n")
+// resolveBaseRef returns the first remote base branch that exists.
+func (m *Manager) resolveBaseRef(ctx context.Context) (string, error) {
+	for _, mainBranch := range m.baseCandidates(ctx) {
+		ref := m.remoteRef(mainBranch)
+		if err := m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", ref); err == nil {
+			return ref, nil
+		}
+	}
 
-	var current *Worktree
-	for _, line := range lines {
+	return "", fmt.Errorf("failed to determine merged branches: no main/master branch found")
+}
+
+// Branches returns the names of all local branches and, if remotes is set,
+// of all remote-tracking branches such as origin/main.
+func (m *Manager) Branches(ctx context.Context, remotes bool) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname:short)", "refs/heads"}
+	if remotes {
+		args = append(args, "refs/remotes")
+	}
+
+	output, err := m.gitOutput(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
-			if current != nil {
-				worktrees = append(worktrees, current)
-				current = nil
-			}
-			continue
+		// Skip symbolic refs such as origin/HEAD
+		if line != "" && !strings.HasSuffix(line, "/HEAD") {
+			branches = append(branches, line)
 		}
+	}
+	return branches, nil
+}
 
-		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
-			current = &Worktree{Path: path}
-		} else if strings.HasPrefix(line, "branch ") && current != nil {
-			branch := strings.TrimPrefix(line, "branch ")
-			if strings.HasPrefix(branch, "refs/heads/") {
-				branch = strings.TrimPrefix(branch, "refs/heads/")
-			}
-			current.Branch = branch
-		} else if strings.HasPrefix(line, "HEAD ") && current != nil {
-			current.Branch = "HEAD"
+// baseCandidates returns the branches that may serve as the repository base:
+// the configured base, the remote HEAD, the forge default branch, then main
+// and master.
+func (m *Manager) baseCandidates(ctx context.Context) []string {
+	var candidates []string
+	if m.cfg.DefaultBase != "" {
+		candidates = append(candidates, m.cfg.DefaultBase)
+	}
+
+	remoteHead := fmt.Sprintf("refs/remotes/%s/HEAD", m.cfg.DefaultRemote)
+	if ref, err := m.gitOutput(ctx, "symbolic-ref", "--short", remoteHead); err == nil {
+		candidates = append(candidates, strings.TrimPrefix(ref, m.cfg.DefaultRemote+"/"))
+	}
+
+	if f, _ := m.Forge(); f != nil && f.Authenticated() {
+		if branch, err := f.DefaultBranch(ctx); err == nil && branch != "" {
+			candidates = append(candidates, branch)
 		}
 	}
 
-	if current != nil {
-		worktrees = append(worktrees, current)
+	return append(candidates, "main", "master")
+}
+
+// remoteRef returns the remote-tracking ref of branch on the default remote.
+func (m *Manager) remoteRef(branch string) string {
+	return fmt.Sprintf("%s/%s", m.cfg.DefaultRemote, branch)
+}
+
+// fastForwardBranch moves branch forward to ref. A branch that already
+// contains ref, for example because of local fixups, is left alone, and one
+// that has diverged from ref is refused.
+func (m *Manager) fastForwardBranch(ctx context.Context, branch, ref string) error {
+	local := "refs/heads/" + branch
+	if m.runGitCommand(ctx, "merge-base", "--is-ancestor", ref, local) == nil {
+		return nil
+	}
+	if m.runGitCommand(ctx, "merge-base", "--is-ancestor", local, ref) != nil {
+		return fmt.Errorf("%w: %s has diverged from the pull request, delete or rebase it", errors.ErrBranchExists, branch)
 	}
+	if err := m.runGitCommand(ctx, "branch", "--force", branch, ref); err != nil {
+		return fmt.Errorf("failed to fast-forward %s: %w", branch, err)
+	}
+	return nil
+}
 
-	return worktrees, nil
+// trackingRef returns the ref branch is compared against for ahead and
+// behind counts: the fetched head of its pull request for branches created
+// by CreateFromPullRequest, and its remote-tracking branch otherwise.
+func (m *Manager) trackingRef(ctx context.Context, branch string) string {
+	if number, ok := pullRequestNumber(branch); ok {
+		ref := pullRequestRef(number)
+		if m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", ref) == nil {
+			return ref
+		}
+	}
+	return m.remoteRef(branch)
+}
+
+// BranchExists reports whether the local branch exists.
+func (m *Manager) BranchExists(ctx context.Context, branch string) bool {
+	return m.runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch) == nil
+}
+
+// RemoteURL returns the URL of the default remote.
+func (m *Manager) RemoteURL() (string, error) {
+	remoteURL, err := m.gitOutput(context.Background(), "remote", "get-url", m.cfg.DefaultRemote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote URL: %w", err)
+	}
+
+	return remoteURL, nil
+}
+
+// GetRepoInfo extracts the repository owner and name from the default remote.
+func (m *Manager) GetRepoInfo() (owner, repo string, err error) {
+	remoteURL, err := m.RemoteURL()
+	if err != nil {
+		return "", "", err
+	}
+
+	remote, err := forge.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse repository info from: %s", remoteURL)
+	}
+
+	return remote.Owner, remote.Name, nil
 }
 
 // enrichWorktree adds status information to a worktree.
 func (m *Manager) enrichWorktree(ctx context.Context, wt *Worktree) error {
-	wt.IsMain = wt.Path == m.repoRoot
-
 	if err := m.getGitStatus(ctx, wt); err != nil {
 		return err
 	}
@@ -233,14 +951,11 @@ func (m *Manager) enrichWorktree(ctx context.Context, wt *Worktree) error {
 
 // getGitStatus populates the status fields of a worktree.
 func (m *Manager) getGitStatus(ctx context.Context, wt *Worktree) error {
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+	statusOutput, err := m.git(ctx, wt.Path, "status", "--porcelain")
 	if err != nil {
 		return err
 	}
 
-	statusOutput := strings.TrimSpace(string(output))
 	wt.IsClean = len(statusOutput) == 0
 
 	if !wt.IsClean {
@@ -267,14 +982,12 @@ func (m *Manager) getGitStatus(ctx context.Context, wt *Worktree) error {
 
 // getCommitInfo populates commit-related fields of a worktree.
 func (m *Manager) getCommitInfo(ctx context.Context, wt *Worktree) error {
-	cmd := exec.CommandContext(ctx, "git", "log", "-1", "--format=%s|%ct")
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+	output, err := m.git(ctx, wt.Path, "log", "-1", "--format=%s|%ct")
 	if err != nil {
 		return err
 	}
 
-	parts := strings.Split(strings.TrimSpace(string(output)), "|")
+	parts := strings.Split(output, "|")
 	if len(parts) >= 2 {
 		wt.LastCommit = parts[0]
 		if timestamp, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
@@ -288,20 +1001,18 @@ func (m *Manager) getCommitInfo(ctx context.Context, wt *Worktree) error {
 
 // getRemoteStatus populates remote tracking information of a worktree.
 func (m *Manager) getRemoteStatus(ctx context.Context, wt *Worktree) error {
-	if wt.Branch == "HEAD" || wt.Branch == "" {
+	if wt.Branch == "" {
 		return nil
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "rev-list", "--count", "--left-right",
-		fmt.Sprintf("origin/%s...HEAD", wt.Branch))
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+	output, err := m.git(ctx, wt.Path, "rev-list", "--count", "--left-right",
+		fmt.Sprintf("%s...HEAD", m.trackingRef(ctx, wt.Branch)))
 	if err != nil {
 		// Not an error if remote branch doesn't exist
 		return nil
 	}
 
-	parts := strings.Fields(strings.TrimSpace(string(output)))
+	parts := strings.Fields(output)
 	if len(parts) >= 2 {
 		if behind, err := strconv.Atoi(parts[0]); err == nil {
 			wt.Behind = behind
@@ -314,32 +1025,6 @@ func (m *Manager) getRemoteStatus(ctx context.Context, wt *Worktree) error {
 	return nil
 }
 
-// runGitCommand runs a git command in the repository root.
-func (m *Manager) runGitCommand(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = m.repoRoot
-	if err := cmd.Run(); err != nil {
-		return errors.NewGitError(args[0], args[1:], err)
-	}
-	return nil
-}
-
-// copyConfigFiles copies configuration files to the new worktree.
-func (m *Manager) copyConfigFiles(destPath string) error {
-	for _, file := range ConfigFiles {
-		srcPath := filepath.Join(m.repoRoot, file)
-		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-			continue
-		}
-
-		destFilePath := filepath.Join(destPath, file)
-		if err := copyFile(srcPath, destFilePath); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // confirmRemoval prompts the user for confirmation.
 func (m *Manager) confirmRemoval(branchName, worktreePath string) bool {
 	fmt.Printf("Remove worktree '%s' at %s? [y/N]: ", branchName, worktreePath)
@@ -355,7 +1040,9 @@ func (m *Manager) parseBranchList(output string) []string {
 	for _, line := range lines {
 		branch := strings.TrimSpace(line)
 		branch = strings.TrimPrefix(branch, "* ")
-		if branch != "" && !isProtectedBranch(branch) {
+		// Branches checked out in other worktrees are marked with "+"
+		branch = strings.TrimPrefix(branch, "+ ")
+		if branch != "" && !m.cfg.IsProtected(branch) {
 			branches = append(branches, branch)
 		}
 	}
@@ -364,25 +1051,32 @@ func (m *Manager) parseBranchList(output string) []string {
 
 // Helper functions
 
-// GetCurrentBranch returns the current branch name.
+// allPatchesUpstream reports whether 'git cherry' output lists at least one
+// commit and every commit has an equivalent patch upstream.
+func allPatchesUpstream(output string) bool {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "- ") {
+			return false
+		}
+	}
+	return len(lines) > 0 && lines[0] != ""
+}
+
+// GetCurrentBranch returns the branch checked out in the worktree giwo runs
+// in, which may be a linked worktree rather than the main one.
 func (m *Manager) GetCurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+	branch, err := m.git(ctx, m.workDir, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
 	
-	branch := strings.TrimSpace(string(output))
 	if branch == "HEAD" {
 		// We're in detached HEAD state, try to get symbolic name
-		cmd = exec.CommandContext(ctx, "git", "describe", "--contains", "--all", "HEAD")
-		cmd.Dir = m.repoRoot
-		output, err = cmd.Output()
+		branch, err = m.git(ctx, m.workDir, "describe", "--contains", "--all", "HEAD")
 		if err != nil {
 			return "", fmt.Errorf("in detached HEAD state and cannot determine branch")
 		}
-		branch = strings.TrimSpace(string(output))
 		// Remove refs/heads/ prefix if present
 		if strings.HasPrefix(branch, "heads/") {
 			branch = strings.TrimPrefix(branch, "heads/")
@@ -392,22 +1086,6 @@ func (m *Manager) GetCurrentBranch(ctx context.Context) (string, error) {
 	return branch, nil
 }
 
-// getGitRoot returns the root directory of the Git repository.
-func getGitRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
-// copyFile copies a file from src to dst.
-func copyFile(src, dst string) error {
-	cmd := exec.Command("cp", src, dst)
-	return cmd.Run()
-}
-
 // formatTimeAgo formats a time duration as a human-readable string.
 func formatTimeAgo(t time.Time) string {
 	duration := time.Since(t)
@@ -426,33 +1104,3 @@ func formatTimeAgo(t time.Time) string {
 		return fmt.Sprintf("%dd ago", days)
 	}
 }
-
-// parseGitHubURL extracts owner and repo from a GitHub URL.
-func parseGitHubURL(url string) (owner, repo string) {
-	url = strings.TrimSpace(url)
-
-	// SSH format: git@github.com:owner/repo.git
-	sshRegex := regexp.MustCompile(`git@github\.com:([^/]+)/(.+?)(?:\.git)?$`)
-	if matches := sshRegex.FindStringSubmatch(url); len(matches) == 3 {
-		return matches[1], matches[2]
-	}
-
-	// HTTPS format: https://github.com/owner/repo.git
-	httpsRegex := regexp.MustCompile(`https://github\.com/([^/]+)/(.+?)(?:\.git)?$`)
-	if matches := httpsRegex.FindStringSubmatch(url); len(matches) == 3 {
-		return matches[1], matches[2]
-	}
-
-	return "", ""
-}
-
-// isProtectedBranch returns true if the branch should not be automatically removed.
-func isProtectedBranch(branch string) bool {
-	protected := []string{"main", "master", "develop", "dev"}
-	for _, p := range protected {
-		if branch == p {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,75 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAllPatchesUpstream(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		expected bool
+	}{
+		"all upstream":      {"- 1111111\n- 2222222\n", true},
+		"one missing":       {"- 1111111\n+ 2222222\n", false},
+		"none upstream":     {"+ 1111111\n", false},
+		"no commits":        {"", false},
+		"whitespace only":   {"\n", false},
+		"single equivalent": {"- 1111111", true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, allPatchesUpstream(tt.output)); diff != "" {
+				t.Errorf("allPatchesUpstream(%q) mismatch (-want +got):\n%s", tt.output, diff)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for name, tt := range map[string]struct {
+		pattern, name string
+		expected      bool
+	}{
+		"literal":                {".env", ".env", true},
+		"star in element":        {".env.*", ".env.local", true},
+		"star stays in element":  {"config/*", "config/local/app.yml", false},
+		"double star any depth":  {"**/*.pem", "certs/dev/key.pem", true},
+		"double star no dirs":    {"**/*.pem", "key.pem", true},
+		"double star in middle":  {"config/**/app.yml", "config/app.yml", true},
+		"double star trailing":   {"config/**", "config/local/app.yml", true},
+		"different directory":    {"config/*.yml", "deploy/app.yml", false},
+		"pattern longer than it": {"config/local/*", "config", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, matchGlob(tt.pattern, tt.name)); diff != "" {
+				t.Errorf("matchGlob(%q, %q) mismatch (-want +got):\n%s", tt.pattern, tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestMatchesBelow(t *testing.T) {
+	for name, tt := range map[string]struct {
+		pattern, dir string
+		expected     bool
+	}{
+		"inside":          {"config/local/*.yml", "config/local", true},
+		"parent":          {"config/local/*.yml", "config", true},
+		"double star":     {"**/*.pem", "certs", true},
+		"other directory": {"config/local/*.yml", "deploy", false},
+		"matches the dir": {"config/*", "config/local", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, matchesBelow(tt.pattern, tt.dir)); diff != "" {
+				t.Errorf("matchesBelow(%q, %q) mismatch (-want +got):\n%s", tt.pattern, tt.dir, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// metadataVersion is the version of the metadata file format.
+const metadataVersion = 1
+
+// Metadata holds what giwo remembers about a worktree beyond what Git records.
+type Metadata struct {
+	// Base is the branch the worktree was created from.
+	Base string `json:"base,omitempty"`
+
+	// CreatedAt is when giwo created the worktree.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+
+	// Description is a free-form note about the purpose of the worktree.
+	Description string `json:"description,omitempty"`
+
+	// Tags are free-form labels.
+	Tags []string `json:"tags,omitempty"`
+
+	// Issue references the issue the worktree is for, such as "123" or "PROJ-42".
+	Issue string `json:"issue,omitempty"`
+
+	// PullRequest is the number of the pull request linked to the worktree.
+	PullRequest int `json:"pull_request,omitempty"`
+
+	// LastAccessedAt is when the worktree was last switched to.
+	LastAccessedAt time.Time `json:"last_accessed_at,omitzero"`
+
+	// Index is a small number, starting at 1, that no other worktree has.
+	// Templates use it to keep worktrees apart.
+	Index int `json:"index,omitempty"`
+
+	// Ports is the block of ports reserved for the dev servers of the
+	// worktree. It is released when the worktree is removed.
+	Ports PortBlock `json:"ports,omitzero"`
+}
+
+// AddTags adds tags that are not present yet, keeping the existing order.
+func (md *Metadata) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if tag != "" && !slices.Contains(md.Tags, tag) {
+			md.Tags = append(md.Tags, tag)
+		}
+	}
+}
+
+// RemoveTags removes tags.
+func (md *Metadata) RemoveTags(tags ...string) {
+	md.Tags = slices.DeleteFunc(md.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	if len(md.Tags) == 0 {
+		md.Tags = nil
+	}
+}
+
+// metadataFile is the on-disk layout of the metadata store.
+type metadataFile struct {
+	Version   int                  `json:"version"`
+	Worktrees map[string]*Metadata `json:"worktrees"`
+}
+
+// MetadataStore persists worktree metadata keyed by branch in a JSON file.
+// Writes replace the file atomically, and updates are serialized across
+// processes by a lock file next to it.
+type MetadataStore struct {
+	path string
+}
+
+// NewMetadataStore returns a store backed by the file at path.
+func NewMetadataStore(path string) *MetadataStore {
+	return &MetadataStore{path: path}
+}
+
+// Path returns the file backing the store.
+func (s *MetadataStore) Path() string {
+	return s.path
+}
+
+// Load returns the metadata of all worktrees keyed by branch.
+// A missing file yields an empty map.
+func (s *MetadataStore) Load() (map[string]*Metadata, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]*Metadata{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var file metadataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata %s: %w", s.path, err)
+	}
+	if file.Worktrees == nil {
+		file.Worktrees = map[string]*Metadata{}
+	}
+	return file.Worktrees, nil
+}
+
+// Get returns the metadata of branch, or nil if none is recorded.
+func (s *MetadataStore) Get(branch string) (*Metadata, error) {
+	all, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return all[branch], nil
+}
+
+// Update applies fn to the metadata of branch, creating the entry if needed,
+// and saves the result.
+func (s *MetadataStore) Update(branch string, fn func(md *Metadata)) error {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	md := all[branch]
+	if md == nil {
+		md = &Metadata{}
+		all[branch] = md
+	}
+	fn(md)
+
+	return s.save(all)
+}
+
+// Delete removes the metadata of branch.
+func (s *MetadataStore) Delete(branch string) error {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[branch]; !ok {
+		return nil
+	}
+
+	delete(all, branch)
+	return s.save(all)
+}
+
+// Rename moves the metadata of oldBranch to newBranch, replacing any
+// metadata newBranch had.
+func (s *MetadataStore) Rename(oldBranch, newBranch string) error {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return err
+	}
+	md, ok := all[oldBranch]
+	if !ok {
+		return nil
+	}
+
+	delete(all, oldBranch)
+	all[newBranch] = md
+	return s.save(all)
+}
+
+// Prune removes the metadata of every branch for which keep returns false,
+// releasing its index and ports, and returns the removed branches in order.
+func (s *MetadataStore) Prune(keep func(branch string) bool) ([]string, error) {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for branch := range all {
+		if !keep(branch) {
+			pruned = append(pruned, branch)
+			delete(all, branch)
+		}
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+	slices.Sort(pruned)
+	return pruned, s.save(all)
+}
+
+// AssignIndex gives branch the smallest index that no other worktree has,
+// unless the index it already has is free. It returns the index of branch.
+func (s *MetadataStore) AssignIndex(branch string) (int, error) {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	used := make(map[int]bool)
+	for other, md := range all {
+		if other != branch && md.Index > 0 {
+			used[md.Index] = true
+		}
+	}
+
+	md := all[branch]
+	if md == nil {
+		md = &Metadata{}
+		all[branch] = md
+	}
+	if md.Index > 0 && !used[md.Index] {
+		return md.Index, nil
+	}
+
+	md.Index = 1
+	for used[md.Index] {
+		md.Index++
+	}
+	return md.Index, s.save(all)
+}
+
+// save writes all to the store file.
+func (s *MetadataStore) save(all map[string]*Metadata) error {
+	data, err := json.MarshalIndent(metadataFile{Version: metadataVersion, Worktrees: all}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// lockPath blocks until it holds an exclusive lock on the file at path and
+// returns the function releasing it. Stores hold the lock from loading the
+// file until saving it, so that concurrent giwo processes neither hand out
+// the same index or ports nor overwrite each other's changes.
+func lockPath(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// over path, so that readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Metadata returns the metadata store of the repository. It lives in the Git
+// common directory so that it is shared by all worktrees and never committed.
+func (m *Manager) Metadata(ctx context.Context) (*MetadataStore, error) {
+	if m.metadata != nil {
+		return m.metadata, nil
+	}
+
+	dir, err := m.GitCommonDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.metadata = NewMetadataStore(filepath.Join(dir, "giwo", "metadata.json"))
+	return m.metadata, nil
+}
+
+// GitCommonDir returns the absolute path of the Git directory shared by all
+// worktrees of the repository.
+func (m *Manager) GitCommonDir(ctx context.Context) (string, error) {
+	dir, err := m.gitOutput(ctx, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(m.repoRoot, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// PruneMetadata removes the metadata of worktrees that no longer exist, such
+// as those removed with 'git worktree remove', and returns their branches.
+// Detached worktrees are kept by the name they were created with.
+func (m *Manager) PruneMetadata(ctx context.Context) ([]string, error) {
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	branches := make(map[string]bool)
+	detached := make(map[string]bool)
+	for _, wt := range worktrees {
+		switch {
+		case wt.Branch != "":
+			branches[wt.Branch] = true
+		case wt.Detached:
+			detached[wt.Path] = true
+		}
+	}
+
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Prune(func(branch string) bool {
+		if branches[branch] {
+			return true
+		}
+		path, err := m.WorktreePath(branch)
+		return err != nil || detached[path]
+	})
+}
+
+// UpdateMetadata applies fn to the metadata of branch and saves it.
+func (m *Manager) UpdateMetadata(ctx context.Context, branch string, fn func(md *Metadata)) error {
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Update(branch, fn)
+}
+
+// MarkAccessed records that the worktree of branch was just accessed.
+func (m *Manager) MarkAccessed(ctx context.Context, branch string) error {
+	return m.UpdateMetadata(ctx, branch, func(md *Metadata) {
+		md.LastAccessedAt = time.Now()
+	})
+}
+
+// recordCreation records the base and creation time of a new worktree,
+// replacing what was left of an earlier worktree of branch that was removed
+// outside giwo. Failures only produce a warning since the worktree itself is
+// usable.
+func (m *Manager) recordCreation(ctx context.Context, branch string, fn func(md *Metadata)) {
+	err := m.UpdateMetadata(ctx, branch, func(md *Metadata) {
+		now := time.Now()
+		*md = Metadata{CreatedAt: now, LastAccessedAt: now}
+		fn(md)
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Warning: failed to record worktree metadata: %v\n", err)
+	}
+}
@@ -0,0 +1,217 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestMetadataStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewMetadataStore(filepath.Join(t.TempDir(), "giwo", "metadata.json"))
+
+	got, err := store.Get("feature-auth")
+	if err != nil {
+		t.Fatalf("Get() on missing file unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("Get() on missing file = %+v, want nil", got)
+	}
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Update("feature-auth", func(md *Metadata) {
+		md.Base = "main"
+		md.CreatedAt = created
+		md.AddTags("auth", "backend", "auth")
+	}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if err := store.Update("feature-auth", func(md *Metadata) {
+		md.Description = "Rework login flow"
+		md.RemoveTags("backend")
+	}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if err := store.Update("bugfix-login", func(md *Metadata) {
+		md.PullRequest = 12
+	}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+
+	all, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	want := map[string]*Metadata{
+		"feature-auth": {
+			Base:        "main",
+			CreatedAt:   created,
+			Description: "Rework login flow",
+			Tags:        []string{"auth"},
+		},
+		"bugfix-login": {PullRequest: 12},
+	}
+	if diff := cmp.Diff(want, all); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := store.Delete("bugfix-login"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if got, _ := store.Get("bugfix-login"); got != nil {
+		t.Errorf("Get() after Delete() = %+v, want nil", got)
+	}
+
+	if err := store.Rename("feature-auth", "feature/auth"); err != nil {
+		t.Fatalf("Rename() unexpected error: %v", err)
+	}
+	all, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]*Metadata{"feature/auth": want["feature-auth"]}, all); diff != "" {
+		t.Errorf("Load() after Rename() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetadataStoreCorrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMetadataStore(path)
+	if _, err := store.Load(); err == nil {
+		t.Error("Load() expected error for corrupt file")
+	}
+	if err := store.Update("feature-auth", func(md *Metadata) {}); err == nil {
+		t.Error("Update() expected error for corrupt file")
+	}
+}
+
+func TestMetadataStoreAssignIndex(t *testing.T) {
+	t.Parallel()
+
+	store := NewMetadataStore(filepath.Join(t.TempDir(), "metadata.json"))
+
+	var got []int
+	for _, branch := range []string{"feature-auth", "bugfix-login", "feature-auth", "spike"} {
+		index, err := store.AssignIndex(branch)
+		if err != nil {
+			t.Fatalf("AssignIndex(%q) unexpected error: %v", branch, err)
+		}
+		got = append(got, index)
+	}
+
+	// A freed index is reused, and a restored index that is taken is not
+	if err := store.Delete("feature-auth"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if err := store.Update("restored", func(md *Metadata) { md.Index = 2 }); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	for _, branch := range []string{"restored", "docs"} {
+		index, err := store.AssignIndex(branch)
+		if err != nil {
+			t.Fatalf("AssignIndex(%q) unexpected error: %v", branch, err)
+		}
+		got = append(got, index)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 1, 3, 1, 4}, got); diff != "" {
+		t.Errorf("AssignIndex() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetadataStoreAssignPorts(t *testing.T) {
+	t.Parallel()
+
+	store := NewMetadataStore(filepath.Join(t.TempDir(), "metadata.json"))
+	assign := func(branch string, size int) string {
+		t.Helper()
+		ports, err := store.AssignPorts(branch, 3000, size)
+		if err != nil {
+			t.Fatalf("AssignPorts(%q) unexpected error: %v", branch, err)
+		}
+		return ports.String()
+	}
+
+	var got []string
+	for _, branch := range []string{"feature-auth", "bugfix-login", "feature-auth"} {
+		got = append(got, assign(branch, 10))
+	}
+
+	// A freed block is reused, a block kept across a size change is not
+	// overlapped, and a restored block that is taken is replaced
+	if err := store.Delete("feature-auth"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	got = append(got, assign("spike", 20))
+	if err := store.Update("restored", func(md *Metadata) { md.Ports = PortBlock{Start: 3015, Count: 10} }); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	got = append(got, assign("restored", 10), assign("bugfix-login", 4), assign("docs", 4))
+
+	want := []string{"3000-3009", "3010-3019", "3000-3009", "3020-3039", "3000-3009", "3010-3019", "3040-3043"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("AssignPorts() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := store.AssignPorts("full", 65530, 10); !errors.Is(err, giwoerrors.ErrNoFreePorts) {
+		t.Errorf("AssignPorts() past the last port error = %v, want %v", err, giwoerrors.ErrNoFreePorts)
+	}
+}
+
+func TestMetadataStoreConcurrentAssign(t *testing.T) {
+	t.Parallel()
+
+	// Each store stands in for a separate giwo process
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewMetadataStore(path)
+			branch := fmt.Sprintf("feature-%d", i)
+			if _, err := store.AssignIndex(branch); err != nil {
+				errs <- err
+			}
+			if _, err := store.AssignPorts(branch, 3000, 10); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Assign unexpected error: %v", err)
+	}
+
+	all, err := NewMetadataStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	indexes := make(map[int]bool)
+	ports := make(map[int]bool)
+	for _, md := range all {
+		indexes[md.Index] = true
+		ports[md.Ports.Start] = true
+	}
+	if len(all) != n || len(indexes) != n || len(ports) != n {
+		t.Errorf("Load() = %d worktrees with %d indexes and %d port blocks, want %d distinct of each", len(all), len(indexes), len(ports), n)
+	}
+}
@@ -0,0 +1,180 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Move renames the branch of the worktree of oldBranch to newBranch and
+// moves the worktree to the path newBranch gets under the current
+// worktree_path template. Its metadata follows the branch, its templates
+// and .envrc are rendered again, and an upstream that tracked the remote
+// branch of the old name is pointed at the new one once that is pushed.
+// Passing the same name only relocates the worktree, for example after
+// changing worktree_path, and a detached worktree is only relocated.
+// It returns the new path of the worktree.
+func (m *Manager) Move(ctx context.Context, oldBranch, newBranch string) (string, error) {
+	wt, err := m.FindWorktree(ctx, oldBranch)
+	if err != nil {
+		return "", err
+	}
+
+	rename := wt.Branch != "" && newBranch != oldBranch
+	if rename && m.BranchExists(ctx, newBranch) {
+		return "", fmt.Errorf("%w: %s", errors.ErrBranchExists, newBranch)
+	}
+
+	newPath, err := m.WorktreePath(newBranch)
+	if err != nil {
+		return "", err
+	}
+	relocate := newPath != wt.Path
+	nested := isWithin(newPath, wt.Path) || isWithin(wt.Path, newPath)
+	// A parent directory of the worktree, as when moving feature/login to
+	// feature, is emptied by moveAside
+	if relocate && !isWithin(wt.Path, newPath) {
+		if _, err := os.Stat(newPath); err == nil {
+			return "", fmt.Errorf("%w: %s", errors.ErrWorktreeExists, newPath)
+		}
+	}
+
+	var undo rollback
+	if relocate {
+		src := wt.Path
+		if nested {
+			// git cannot move a worktree into itself or onto one of its
+			// parent directories, so step aside first
+			if src, err = m.moveAside(ctx, &undo, wt.Path, newPath); err != nil {
+				undo.run(ctx)
+				return "", err
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+			undo.run(ctx)
+			return "", fmt.Errorf("failed to create worktree directory: %w", err)
+		}
+		if err := m.runGitCommand(ctx, "worktree", "move", src, newPath); err != nil {
+			undo.run(ctx)
+			return "", fmt.Errorf("failed to move worktree: %w", err)
+		}
+		undo.add(func(ctx context.Context) error {
+			if err := os.MkdirAll(filepath.Dir(src), 0o755); err != nil {
+				return err
+			}
+			return m.runGitCommand(ctx, "worktree", "move", newPath, src)
+		})
+		if nested {
+			os.Remove(filepath.Dir(src))
+		}
+		removeEmptyDirs(filepath.Dir(wt.Path), m.worktreeBase())
+	}
+
+	if rename {
+		if err := m.runGitCommand(ctx, "branch", "-m", oldBranch, newBranch); err != nil {
+			undo.run(ctx)
+			return "", fmt.Errorf("failed to rename branch: %w", err)
+		}
+		if err := m.renameUpstream(ctx, oldBranch, newBranch); err != nil {
+			fmt.Printf("⚠️  Warning: failed to update upstream: %v\n", err)
+		}
+		store, err := m.Metadata(ctx)
+		if err == nil {
+			err = store.Rename(oldBranch, newBranch)
+		}
+		if err != nil {
+			fmt.Printf("⚠️  Warning: failed to move worktree metadata: %v\n", err)
+		}
+	}
+
+	if relocate || rename {
+		name := oldBranch
+		if rename {
+			name = newBranch
+		}
+		m.refreshWorktree(ctx, name, newPath)
+	}
+
+	return newPath, nil
+}
+
+// moveAside moves the worktree at path to a temporary directory next to
+// the shallower of path and dest and removes the directories that it leaves
+// empty up to dest. It returns the temporary path of the worktree.
+func (m *Manager) moveAside(ctx context.Context, undo *rollback, path, dest string) (string, error) {
+	parent := filepath.Dir(path)
+	if len(dest) < len(path) {
+		parent = filepath.Dir(dest)
+	}
+	tmp, err := os.MkdirTemp(parent, ".giwo-move-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	aside := filepath.Join(tmp, "worktree")
+	if err := m.runGitCommand(ctx, "worktree", "move", path, aside); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to move worktree: %w", err)
+	}
+	undo.add(func(ctx context.Context) error {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := m.runGitCommand(ctx, "worktree", "move", aside, path); err != nil {
+			return err
+		}
+		return os.Remove(tmp)
+	})
+
+	removeEmptyDirs(filepath.Dir(path), filepath.Dir(dest))
+	return aside, nil
+}
+
+// renameUpstream points the upstream of newBranch, just renamed from
+// oldBranch, at the remote branch of the new name if it tracked the remote
+// branch of the old name and the new one has been pushed already. Until
+// then the old upstream is kept, so that pulling keeps working. Other
+// upstreams, such as pull request refs, are left alone.
+func (m *Manager) renameUpstream(ctx context.Context, oldBranch, newBranch string) error {
+	key := fmt.Sprintf("branch.%s.merge", newBranch)
+	merge, err := m.gitOutput(ctx, "config", "--get", key)
+	if err != nil || merge != "refs/heads/"+oldBranch {
+		return nil
+	}
+	remote, err := m.gitOutput(ctx, "config", "--get", fmt.Sprintf("branch.%s.remote", newBranch))
+	if err != nil || remote == "." {
+		return nil
+	}
+	if _, err := m.gitOutput(ctx, "rev-parse", "--verify", "--quiet", fmt.Sprintf("refs/remotes/%s/%s", remote, newBranch)); err != nil {
+		return nil
+	}
+	return m.runGitCommand(ctx, "config", key, "refs/heads/"+newBranch)
+}
+
+// refreshWorktree renders the templates and rewrites the .envrc of the
+// worktree of name at path again, since they depend on its branch and
+// path. Failures only produce warnings since the worktree was moved.
+func (m *Manager) refreshWorktree(ctx context.Context, name, path string) {
+	var base string
+	if store, err := m.Metadata(ctx); err == nil {
+		if md, err := store.Get(name); err == nil && md != nil {
+			base = md.Base
+		}
+	}
+
+	data, err := m.templateData(ctx, name, path, base)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: %v, skipping templates\n", err)
+	} else if err := m.renderTemplates(ctx, path, data); err != nil {
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	}
+	if m.cfg.Env.Envrc {
+		if err := m.writeEnvrc(ctx, data); err != nil {
+			fmt.Printf("⚠️  Warning: failed to write %s: %v\n", EnvrcFile, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestManagerMove(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	writeTestFile(t, filepath.Join(repo, ".env.giwo.tmpl"), "BRANCH={{.Branch}}\n")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.Env.Envrc = true
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	opts := worktree.CreateOptions{From: "main", Fetch: config.FetchNever}
+	for _, branch := range []string{"feature", "other"} {
+		if _, err := m.Create(ctx, branch, opts); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", branch, err)
+		}
+	}
+	runGit(t, repo, "config", "branch.feature.remote", "origin")
+	runGit(t, repo, "config", "branch.feature.merge", "refs/heads/feature")
+	if err := m.UpdateMetadata(ctx, "feature", func(md *worktree.Metadata) {
+		md.Description = "Rework login flow"
+	}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+
+	if _, err := m.Move(ctx, "feature", "other"); !errors.Is(err, giwoerrors.ErrBranchExists) {
+		t.Errorf("Move() onto an existing branch error = %v, want %v", err, giwoerrors.ErrBranchExists)
+	}
+
+	path, err := m.Move(ctx, "feature", "feature/login")
+	if err != nil {
+		t.Fatalf("Move() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(filepath.Join(m.WorktreeDir(), "feature", "login"), path); diff != "" {
+		t.Errorf("Move() path mismatch (-want +got):\n%s", diff)
+	}
+	if got := strings.TrimSpace(runGit(t, path, "branch", "--show-current")); got != "feature/login" {
+		t.Errorf("branch after Move() = %q, want %q", got, "feature/login")
+	}
+	if m.BranchExists(ctx, "feature") {
+		t.Errorf("old branch still exists after Move()")
+	}
+	// The new name is not pushed yet, so pulling still uses the old one
+	if got := strings.TrimSpace(runGit(t, repo, "config", "branch.feature/login.merge")); got != "refs/heads/feature" {
+		t.Errorf("upstream after Move() = %q, want %q", got, "refs/heads/feature")
+	}
+	if content, err := os.ReadFile(filepath.Join(path, ".env")); err != nil || string(content) != "BRANCH=feature/login\n" {
+		t.Errorf("rendered .env after Move() = %q, %v, want the new branch", content, err)
+	}
+	if content, err := os.ReadFile(filepath.Join(path, worktree.EnvrcFile)); err != nil || !strings.Contains(string(content), path) {
+		t.Errorf("%s after Move() = %q, %v, want the new path", worktree.EnvrcFile, content, err)
+	}
+
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		t.Fatalf("Metadata() unexpected error: %v", err)
+	}
+	if md, _ := store.Get("feature/login"); md == nil || md.Description != "Rework login flow" {
+		t.Errorf("metadata after Move() = %+v, want the description of feature", md)
+	}
+
+	// Back onto what is now the parent directory of the worktree
+	path, err = m.Move(ctx, "feature/login", "feature")
+	if err != nil {
+		t.Fatalf("Move() back unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(filepath.Join(m.WorktreeDir(), "feature"), path); diff != "" {
+		t.Errorf("Move() back path mismatch (-want +got):\n%s", diff)
+	}
+
+	// Relocate to a new layout without renaming
+	cfg.WorktreePath = "../{repo}-{slug}"
+	path, err = m.Move(ctx, "feature", "feature")
+	if err != nil {
+		t.Fatalf("Move() to a new layout unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(filepath.Join(filepath.Dir(repo), filepath.Base(repo)+"-feature"), path); diff != "" {
+		t.Errorf("Move() to a new layout path mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		t.Errorf("worktree missing after relocation: %v", err)
+	}
+	if entries, _ := os.ReadDir(m.WorktreeDir()); len(entries) != 1 || entries[0].Name() != "other" {
+		t.Errorf("worktree directory after relocation = %v, want only other", entries)
+	}
+
+	// A pushed new name becomes the upstream
+	runGit(t, repo, "config", "branch.other.remote", "origin")
+	runGit(t, repo, "config", "branch.other.merge", "refs/heads/other")
+	runGit(t, repo, "update-ref", "refs/remotes/origin/renamed", "main")
+	if _, err := m.Move(ctx, "other", "renamed"); err != nil {
+		t.Fatalf("Move() to a pushed name unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(runGit(t, repo, "config", "branch.renamed.merge")); got != "refs/heads/renamed" {
+		t.Errorf("upstream after Move() to a pushed name = %q, want %q", got, "refs/heads/renamed")
+	}
+}
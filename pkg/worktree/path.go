@@ -0,0 +1,116 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/config"
+)
+
+// WorktreePath returns the path a new worktree of branch is created at,
+// following the worktree_path template. Existing worktrees may live
+// elsewhere, so use FindWorktree to locate them.
+func (m *Manager) WorktreePath(branch string) (string, error) {
+	vars, err := m.pathVars(branch)
+	if err != nil {
+		return "", err
+	}
+	return m.cfg.ResolveWorktreePath(vars), nil
+}
+
+// worktreeBase returns the directory the worktree_path template puts all
+// worktrees in. Directories left empty by removed worktrees are cleaned up
+// to there. It falls back to the worktree directory if the template cannot
+// be rendered.
+func (m *Manager) worktreeBase() string {
+	vars, err := m.pathVars("")
+	if err != nil {
+		return m.worktreeDir
+	}
+	return m.cfg.ResolveWorktreeBase(vars)
+}
+
+// pathVars returns the values substituted into the worktree_path template
+// for branch.
+func (m *Manager) pathVars(branch string) (config.PathVars, error) {
+	vars := config.PathVars{
+		Root:   m.repoRoot,
+		Repo:   filepath.Base(m.repoRoot),
+		Branch: branch,
+	}
+	if strings.Contains(m.cfg.PathTemplate(), "{owner}") {
+		owner, _, err := m.GetRepoInfo()
+		if err != nil {
+			return vars, fmt.Errorf("failed to resolve {owner} in worktree_path: %w", err)
+		}
+		vars.Owner = owner
+	}
+	return vars, nil
+}
+
+// FindWorktree returns the linked worktree that has branch checked out,
+// wherever it lives. A detached worktree has no branch and is found by the
+// path it was created at for the name branch.
+func (m *Manager) FindWorktree(ctx context.Context, branch string) (*Worktree, error) {
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wt := range worktrees {
+		if !wt.IsMain && wt.Branch == branch {
+			return wt, nil
+		}
+	}
+
+	if path, err := m.WorktreePath(branch); err == nil {
+		for _, wt := range worktrees {
+			if !wt.IsMain && wt.Branch == "" && wt.Path == path {
+				return wt, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, branch)
+}
+
+// CurrentWorktree returns the worktree giwo runs in. Worktrees nested inside
+// the main worktree take precedence over it.
+func (m *Manager) CurrentWorktree(ctx context.Context) (*Worktree, error) {
+	dir := m.workDir
+	worktrees, err := m.Worktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var current *Worktree
+	for _, wt := range worktrees {
+		if wt.Bare || !isWithin(dir, wt.Path) {
+			continue
+		}
+		if current == nil || len(wt.Path) > len(current.Path) {
+			current = wt
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: %s is not inside a worktree", errors.ErrWorktreeNotFound, dir)
+	}
+	return current, nil
+}
+
+// isWithin reports whether path is dir or inside it.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// removeEmptyDirs removes dir and then its parents while they are empty and
+// strictly inside stop.
+func removeEmptyDirs(dir, stop string) {
+	for dir != stop && isWithin(dir, stop) && os.Remove(dir) == nil {
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,61 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree/worktreetest"
+)
+
+func TestManagerFindWorktree(t *testing.T) {
+	for name, tt := range map[string]struct {
+		branch   string
+		wantPath string
+		wantErr  error
+	}{
+		"branch outside the worktree dir": {
+			branch:   "feature/login",
+			wantPath: "/src/repo-feature-login",
+		},
+		"detached by name": {
+			branch:   "v1.2.0",
+			wantPath: fakeRepoRoot + "/.worktree/v1.2.0",
+		},
+		"main worktree": {
+			branch:  "main",
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+		"missing": {
+			branch:  "feature/signup",
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			runner.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot) +
+				porcelainEntry("/src/repo-feature-login", "2222222222222222222222222222222222222222", "branch refs/heads/feature/login") +
+				porcelainEntry(fakeRepoRoot+"/.worktree/v1.2.0", "3333333333333333333333333333333333333333", "detached"))
+
+			wt, err := m.FindWorktree(context.Background(), tt.branch)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("FindWorktree() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindWorktree() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantPath, wt.Path); diff != "" {
+				t.Errorf("FindWorktree() path mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
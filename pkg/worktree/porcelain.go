@@ -0,0 +1,80 @@
+package worktree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseWorktreeList parses the output of 'git worktree list --porcelain',
+// with or without -z. Each worktree is a record of "<attribute> [<value>]"
+// lines that starts with "worktree <path>" and ends with an empty line.
+// Attributes added by newer versions of git are ignored.
+func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
+	// Without -z, git quotes lock and prune reasons that contain newlines or
+	// other special characters. Paths are printed as they are.
+	sep, quoted := "\n", true
+	if strings.Contains(output, "\x00") {
+		sep, quoted = "\x00", false
+	}
+
+	var worktrees []*Worktree
+	var current *Worktree
+	for _, line := range strings.Split(output, sep) {
+		if line == "" {
+			if current != nil {
+				worktrees = append(worktrees, current)
+				current = nil
+			}
+			continue
+		}
+
+		attr, value, _ := strings.Cut(line, " ")
+		if attr == "worktree" {
+			if current != nil {
+				worktrees = append(worktrees, current)
+			}
+			current = &Worktree{Path: value, IsMain: value == m.repoRoot}
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("unexpected %q before the first worktree", line)
+		}
+
+		switch attr {
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			current.Bare = true
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = unquoteReason(value, quoted)
+		case "prunable":
+			current.Prunable = true
+			current.PrunableReason = unquoteReason(value, quoted)
+		}
+	}
+
+	if current != nil {
+		worktrees = append(worktrees, current)
+	}
+
+	return worktrees, nil
+}
+
+// unquoteReason undoes the C-style quoting git applies to lock and prune
+// reasons when quoted is set. Reasons that are not quoted, or cannot be
+// unquoted, are returned as they are.
+func unquoteReason(reason string, quoted bool) string {
+	if !quoted || !strings.HasPrefix(reason, `"`) {
+		return reason
+	}
+	if s, err := strconv.Unquote(reason); err == nil {
+		return s
+	}
+	return reason
+}
@@ -0,0 +1,100 @@
+package worktree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	sampleRoot = "/tmp/pz/r"
+	sampleHead = "a7b97f67e98a991ac17d62fb9d959cffa8503e62"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	main := &Worktree{Path: sampleRoot, Branch: "main", Head: sampleHead, IsMain: true}
+
+	for name, tt := range map[string]struct {
+		output string
+		want   []*Worktree
+	}{
+		// git 2.7 to 2.30 print neither locked nor prunable
+		"git 2.30": {
+			output: lines(
+				"worktree "+sampleRoot, "HEAD "+sampleHead, "branch refs/heads/main", "",
+				"worktree /tmp/pz/det", "HEAD "+sampleHead, "detached", "",
+			),
+			want: []*Worktree{
+				main,
+				{Path: "/tmp/pz/det", Head: sampleHead, Detached: true},
+			},
+		},
+		// git 2.31 added locked and prunable, quoting reasons with newlines
+		"git 2.31 locked and prunable": {
+			output: lines(
+				"worktree "+sampleRoot, "HEAD "+sampleHead, "branch refs/heads/main", "",
+				"worktree /tmp/pz/det", "HEAD "+sampleHead, "detached", `locked "on a\nUSB \"drive\""`, "",
+				"worktree /tmp/pz/gone", "HEAD "+sampleHead, "branch refs/heads/gone", "prunable gitdir file points to non-existent location", "",
+				"worktree /tmp/pz/with space", "HEAD "+sampleHead, "branch refs/heads/sp", "locked", "",
+			),
+			want: []*Worktree{
+				main,
+				{Path: "/tmp/pz/det", Head: sampleHead, Detached: true, Locked: true, LockReason: "on a\nUSB \"drive\""},
+				{Path: "/tmp/pz/gone", Branch: "gone", Head: sampleHead, Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+				{Path: "/tmp/pz/with space", Branch: "sp", Head: sampleHead, Locked: true},
+			},
+		},
+		// git 2.36 added -z, which leaves paths and reasons as they are
+		"git 2.36 -z": {
+			output: "worktree " + sampleRoot + "\x00HEAD " + sampleHead + "\x00branch refs/heads/main\x00\x00" +
+				"worktree /tmp/pz/det\x00HEAD " + sampleHead + "\x00detached\x00locked on a\nUSB \"drive\"\x00\x00" +
+				"worktree /tmp/pz/new\nline\x00HEAD " + sampleHead + "\x00branch refs/heads/nl\x00\x00",
+			want: []*Worktree{
+				main,
+				{Path: "/tmp/pz/det", Head: sampleHead, Detached: true, Locked: true, LockReason: "on a\nUSB \"drive\""},
+				{Path: "/tmp/pz/new\nline", Branch: "nl", Head: sampleHead},
+			},
+		},
+		"bare repository": {
+			output: "worktree /tmp/pz/bare.git\x00bare\x00\x00" +
+				"worktree /tmp/pz/bw\x00HEAD " + sampleHead + "\x00branch refs/heads/main\x00\x00",
+			want: []*Worktree{
+				{Path: "/tmp/pz/bare.git", Bare: true},
+				{Path: "/tmp/pz/bw", Branch: "main", Head: sampleHead},
+			},
+		},
+		"unknown attributes and no trailing empty line": {
+			output: lines("worktree "+sampleRoot, "HEAD "+sampleHead, "branch refs/heads/main", "sparse"),
+			want:   []*Worktree{main},
+		},
+		"empty": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &Manager{repoRoot: sampleRoot}
+			got, err := m.parseWorktreeList(tt.output)
+			if err != nil {
+				t.Fatalf("parseWorktreeList() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseWorktreeList() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseWorktreeListMalformed(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{repoRoot: sampleRoot}
+	if _, err := m.parseWorktreeList("HEAD " + sampleHead + "\n"); err == nil {
+		t.Errorf("parseWorktreeList() error = nil, want an error for an attribute before the first worktree")
+	}
+}
+
+// lines joins porcelain lines as 'git worktree list --porcelain' prints them.
+func lines(l ...string) string {
+	return strings.Join(l, "\n") + "\n"
+}
@@ -0,0 +1,113 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// maxPort is the highest TCP port.
+const maxPort = 65535
+
+// PortBlock is a range of consecutive ports reserved for a worktree.
+type PortBlock struct {
+	// Start is the first port of the block.
+	Start int `json:"start"`
+	// Count is the number of ports in the block.
+	Count int `json:"count"`
+}
+
+// IsZero reports whether b reserves no ports.
+func (b PortBlock) IsZero() bool {
+	return b.Count == 0
+}
+
+// Ports returns the ports of the block in order.
+func (b PortBlock) Ports() []int {
+	ports := make([]int, b.Count)
+	for i := range ports {
+		ports[i] = b.Start + i
+	}
+	return ports
+}
+
+// String returns the block as "first-last", or "" if it is empty.
+func (b PortBlock) String() string {
+	switch b.Count {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprint(b.Start)
+	default:
+		return fmt.Sprintf("%d-%d", b.Start, b.Start+b.Count-1)
+	}
+}
+
+// overlaps reports whether b and other share a port.
+func (b PortBlock) overlaps(other PortBlock) bool {
+	return b.Start < other.Start+other.Count && other.Start < b.Start+b.Count
+}
+
+// AssignPorts reserves a block of size ports for branch and returns it. The
+// block branch already has is kept as long as no other worktree has reserved
+// any of its ports, even if size changed since. Otherwise branch gets the
+// first free block of those laid out from base on.
+func (s *MetadataStore) AssignPorts(branch string, base, size int) (PortBlock, error) {
+	unlock, err := lockPath(s.path)
+	if err != nil {
+		return PortBlock{}, err
+	}
+	defer unlock()
+
+	all, err := s.Load()
+	if err != nil {
+		return PortBlock{}, err
+	}
+
+	var used []PortBlock
+	for other, md := range all {
+		if other != branch && !md.Ports.IsZero() {
+			used = append(used, md.Ports)
+		}
+	}
+	free := func(b PortBlock) bool {
+		for _, u := range used {
+			if b.overlaps(u) {
+				return false
+			}
+		}
+		return true
+	}
+
+	md := all[branch]
+	if md == nil {
+		md = &Metadata{}
+		all[branch] = md
+	}
+	if !md.Ports.IsZero() && free(md.Ports) {
+		return md.Ports, nil
+	}
+
+	for start := base; size > 0 && start+size-1 <= maxPort; start += size {
+		if block := (PortBlock{Start: start, Count: size}); free(block) {
+			md.Ports = block
+			return block, s.save(all)
+		}
+	}
+	return PortBlock{}, fmt.Errorf("%w: all blocks of %d ports from %d are taken", errors.ErrNoFreePorts, size, base)
+}
+
+// recordedPorts returns the block of ports reserved for the worktree of
+// branch, or an empty block if it has none.
+func (m *Manager) recordedPorts(ctx context.Context, branch string) PortBlock {
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		return PortBlock{}
+	}
+	md, err := store.Get(branch)
+	if err != nil || md == nil {
+		return PortBlock{}
+	}
+	return md.Ports
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package worktree
+
+import "os/exec"
+
+// killProcessGroupOnCancel is a no-op on platforms without process groups.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
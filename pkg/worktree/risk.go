@@ -0,0 +1,155 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Risk is a way in which removing a worktree may lose work or disrupt the user.
+type Risk string
+
+// Risk constants.
+const (
+	// RiskUncommitted means tracked files have uncommitted changes.
+	RiskUncommitted Risk = "uncommitted"
+	// RiskUntracked means the worktree contains untracked files.
+	RiskUntracked Risk = "untracked"
+	// RiskUnpushed means the branch has commits that are on no remote.
+	RiskUnpushed Risk = "unpushed"
+	// RiskUnmerged means the branch has not landed in the base branch.
+	RiskUnmerged Risk = "unmerged"
+	// RiskLocked means the worktree is locked with 'git worktree lock'.
+	RiskLocked Risk = "locked"
+	// RiskInUse means a process other than giwo and its parent shell has its
+	// current directory inside the worktree.
+	RiskInUse Risk = "in-use"
+)
+
+// Risks lists all risks, in the order they are reported.
+var Risks = []Risk{RiskUncommitted, RiskUntracked, RiskUnpushed, RiskUnmerged, RiskLocked, RiskInUse}
+
+// ParseRisks parses a comma-separated list of risk names. "all" accepts
+// every risk.
+func ParseRisks(s string) ([]Risk, error) {
+	var risks []Risk
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case name == "all":
+			return slices.Clone(Risks), nil
+		case slices.Contains(Risks, Risk(name)):
+			risks = append(risks, Risk(name))
+		default:
+			return nil, fmt.Errorf("unknown risk %q (valid: %s, all)", name, joinRisks(Risks, ", "))
+		}
+	}
+	return risks, nil
+}
+
+// Finding is a risk detected by Preflight.
+type Finding struct {
+	Risk   Risk   `json:"risk"`
+	Detail string `json:"detail"`
+}
+
+// Preflight inspects the worktree of branch and returns the risks of
+// removing it. Branch risks are skipped when keepBranch is set, since the
+// branch and its commits survive the removal, and for detached worktrees.
+func (m *Manager) Preflight(ctx context.Context, branch string, keepBranch bool) ([]Finding, error) {
+	wt, err := m.FindWorktree(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+	return m.preflight(ctx, wt, keepBranch)
+}
+
+// preflight returns the risks of removing wt.
+func (m *Manager) preflight(ctx context.Context, wt *Worktree, keepBranch bool) ([]Finding, error) {
+	var findings []Finding
+	branch := wt.Branch
+
+	status, err := m.git(ctx, wt.Path, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var changed, untracked int
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "??"):
+			untracked++
+		default:
+			changed++
+		}
+	}
+	if changed > 0 {
+		findings = append(findings, Finding{RiskUncommitted, fmt.Sprintf("%d uncommitted change(s)", changed)})
+	}
+	if untracked > 0 {
+		findings = append(findings, Finding{RiskUntracked, fmt.Sprintf("%d untracked file(s)", untracked)})
+	}
+
+	// Commits that landed in the base branch are safe even if the branch
+	// itself was never pushed, so only unmerged branches are checked for
+	// commits missing from every remote and every fetched pull request.
+	if !keepBranch && branch != "" {
+		if detail, merged := m.mergeStatus(ctx, branch); !merged {
+			count, err := m.gitOutput(ctx, "rev-list", "--count", branch, "--not", "--remotes", "--glob="+pullRefPrefix+"*")
+			if n, _ := strconv.Atoi(count); err == nil && n > 0 {
+				findings = append(findings, Finding{RiskUnpushed, fmt.Sprintf("%d commit(s) not pushed to any remote", n)})
+			}
+			findings = append(findings, Finding{RiskUnmerged, detail})
+		}
+	}
+
+	if wt.Locked {
+		detail := "worktree is locked"
+		if wt.LockReason != "" {
+			detail += ": " + wt.LockReason
+		}
+		findings = append(findings, Finding{RiskLocked, detail})
+	}
+
+	if users := processesUsing(wt.Path); len(users) > 0 {
+		findings = append(findings, Finding{RiskInUse, "in use by " + strings.Join(users, ", ")})
+	}
+
+	return findings, nil
+}
+
+// mergeStatus reports whether branch has landed in the base branch and, if
+// not, why.
+func (m *Manager) mergeStatus(ctx context.Context, branch string) (string, bool) {
+	baseRef, err := m.resolveBaseRef(ctx)
+	if err != nil {
+		return "no base branch to compare against", false
+	}
+
+	if err := m.runGitCommand(ctx, "merge-base", "--is-ancestor", branch, baseRef); err == nil {
+		return "", true
+	}
+
+	f, _ := m.Forge()
+	if f != nil && !f.Authenticated() {
+		f = nil
+	}
+	if _, ok := m.detectMerge(ctx, branch, baseRef, f); ok {
+		return "", true
+	}
+
+	return fmt.Sprintf("not merged into %s", baseRef), false
+}
+
+// joinRisks joins the names of risks with sep.
+func joinRisks(risks []Risk, sep string) string {
+	names := make([]string, len(risks))
+	for i, risk := range risks {
+		names[i] = string(risk)
+	}
+	return strings.Join(names, sep)
+}
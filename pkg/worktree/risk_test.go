@@ -0,0 +1,228 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/knwoop/giwo/pkg/worktree/worktreetest"
+)
+
+const featurePath = fakeRepoRoot + "/.worktree/feature-auth"
+
+// scriptFeatureWorktree scripts a clean and merged feature-auth worktree.
+// Tests override the responses for the risks they exercise.
+func scriptFeatureWorktree(r *worktreetest.FakeRunner) {
+	r.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot, featurePath))
+	r.On("status", "--porcelain").InDir(featurePath)
+	r.On("rev-parse", "--verify", "--quiet", "origin/main").Return("3333333333333333333333333333333333333333\n")
+	r.On("merge-base", "--is-ancestor", "feature-auth", "origin/main")
+	r.On("rev-list", "--count", "feature-auth", "--not", "--remotes", "--glob=refs/giwo/pull/*").Return("0\n")
+}
+
+// unmerged scripts feature-auth as not merged into origin/main.
+func unmerged(r *worktreetest.FakeRunner) {
+	r.On("merge-base", "--is-ancestor", "feature-auth", "origin/main").Fail("")
+}
+
+func TestParseRisks(t *testing.T) {
+	for name, tt := range map[string]struct {
+		input   string
+		want    []worktree.Risk
+		wantErr bool
+	}{
+		"single": {
+			input: "uncommitted",
+			want:  []worktree.Risk{worktree.RiskUncommitted},
+		},
+		"list with spaces": {
+			input: "unpushed, locked",
+			want:  []worktree.Risk{worktree.RiskUnpushed, worktree.RiskLocked},
+		},
+		"all": {
+			input: "all",
+			want:  worktree.Risks,
+		},
+		"empty": {
+			input: "",
+		},
+		"unknown": {
+			input:   "uncommitted,dirty",
+			wantErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := worktree.ParseRisks(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRisks(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRisks(%q) unexpected error: %v", tt.input, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseRisks(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func TestManagerPreflight(t *testing.T) {
+	for name, tt := range map[string]struct {
+		script     func(r *worktreetest.FakeRunner)
+		keepBranch bool
+		want       []worktree.Finding
+	}{
+		"safe": {
+			script: func(r *worktreetest.FakeRunner) {},
+		},
+		"uncommitted and untracked": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("status", "--porcelain").InDir(featurePath).Return(" M auth.go\nA  login.go\n?? notes.txt\n")
+			},
+			want: []worktree.Finding{
+				{Risk: worktree.RiskUncommitted, Detail: "2 uncommitted change(s)"},
+				{Risk: worktree.RiskUntracked, Detail: "1 untracked file(s)"},
+			},
+		},
+		"unmerged but pushed": {
+			script: unmerged,
+			want: []worktree.Finding{
+				{Risk: worktree.RiskUnmerged, Detail: "not merged into origin/main"},
+			},
+		},
+		"unmerged and unpushed": {
+			script: func(r *worktreetest.FakeRunner) {
+				unmerged(r)
+				r.On("rev-list", "--count", "feature-auth", "--not", "--remotes", "--glob=refs/giwo/pull/*").Return("3\n")
+			},
+			want: []worktree.Finding{
+				{Risk: worktree.RiskUnpushed, Detail: "3 commit(s) not pushed to any remote"},
+				{Risk: worktree.RiskUnmerged, Detail: "not merged into origin/main"},
+			},
+		},
+		"merged but unpushed": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("rev-list", "--count", "feature-auth", "--not", "--remotes", "--glob=refs/giwo/pull/*").Return("3\n")
+			},
+		},
+		"branch risks ignored when keeping branch": {
+			script:     unmerged,
+			keepBranch: true,
+		},
+		"locked": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("worktree", "list", "--porcelain", "-z").Return(porcelainList(fakeRepoRoot) +
+					porcelainEntry(featurePath, "2222222222222222222222222222222222222222", "branch refs/heads/feature-auth", "locked on a removable drive"))
+			},
+			want: []worktree.Finding{
+				{Risk: worktree.RiskLocked, Detail: "worktree is locked: on a removable drive"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			scriptFeatureWorktree(runner)
+			tt.script(runner)
+
+			got, err := m.Preflight(context.Background(), "feature-auth", tt.keepBranch)
+			if err != nil {
+				t.Fatalf("Preflight() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Preflight() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerRemove(t *testing.T) {
+	for name, tt := range map[string]struct {
+		script     func(r *worktreetest.FakeRunner)
+		opts       worktree.RemoveOptions
+		wantRisks  []string
+		wantRemove string
+	}{
+		"safe worktree": {
+			script:     func(r *worktreetest.FakeRunner) {},
+			opts:       worktree.RemoveOptions{Yes: true, NoArchive: true},
+			wantRemove: "worktree remove " + featurePath,
+		},
+		"refuses unaccepted risks": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("status", "--porcelain").InDir(featurePath).Return(" M auth.go\n")
+				unmerged(r)
+			},
+			opts:      worktree.RemoveOptions{Yes: true, Accept: []worktree.Risk{worktree.RiskUncommitted}},
+			wantRisks: []string{"unmerged"},
+		},
+		"forces accepted uncommitted changes": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("status", "--porcelain").InDir(featurePath).Return(" M auth.go\n")
+			},
+			opts:       worktree.RemoveOptions{Yes: true, Accept: []worktree.Risk{worktree.RiskUncommitted}, NoArchive: true},
+			wantRemove: "worktree remove --force " + featurePath,
+		},
+		"bare yes does not force": {
+			script: func(r *worktreetest.FakeRunner) {
+				r.On("status", "--porcelain").InDir(featurePath).Return("?? notes.txt\n")
+			},
+			opts:      worktree.RemoveOptions{Yes: true},
+			wantRisks: []string{"untracked"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := worktreetest.NewFakeRunner()
+			m := newFakeManager(t, runner)
+			scriptFeatureWorktree(runner)
+			runner.On("worktree", "remove", featurePath)
+			runner.On("worktree", "remove", "--force", featurePath)
+			runner.On("branch", "-D", "feature-auth")
+			tt.script(runner)
+
+			err := m.Remove(context.Background(), "feature-auth", tt.opts)
+
+			var riskErr *giwoerrors.RiskError
+			if tt.wantRisks != nil {
+				if !errors.As(err, &riskErr) {
+					t.Fatalf("Remove() error = %v, want RiskError", err)
+				}
+				if diff := cmp.Diff(tt.wantRisks, riskErr.Risks); diff != "" {
+					t.Errorf("RiskError.Risks mismatch (-want +got):\n%s", diff)
+				}
+				for _, line := range runner.CommandLines() {
+					if strings.HasPrefix(line, "worktree remove") || strings.HasPrefix(line, "branch -D") {
+						t.Errorf("Remove() ran %q after refusing", line)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Remove() unexpected error: %v", err)
+			}
+
+			lines := runner.CommandLines()
+			if !slices.Contains(lines, tt.wantRemove) {
+				t.Errorf("Remove() commands %q do not contain %q", lines, tt.wantRemove)
+			}
+			if !slices.Contains(lines, "branch -D feature-auth") {
+				t.Errorf("Remove() commands %q do not delete the branch", lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rollback collects the steps that undo a partially completed change to a
+// worktree, such as creating or moving it.
+type rollback []func(ctx context.Context) error
+
+// add records step, which runs before the steps recorded earlier.
+func (r *rollback) add(step func(ctx context.Context) error) {
+	*r = append(*r, step)
+}
+
+// run undoes the recorded steps in reverse order. It ignores the
+// cancellation of ctx, so that an interrupted change is cleaned up as well,
+// and only warns about steps that fail.
+func (r rollback) run(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	fmt.Println("↩️  Rolling back...")
+	for i := len(r) - 1; i >= 0; i-- {
+		if err := r[i](ctx); err != nil {
+			fmt.Printf("⚠️  Warning: failed to roll back: %v\n", err)
+		}
+	}
+}
+
+// addWorktree runs the 'git worktree add' args, which check out branch at
+// path and create the branch if newBranch is set. It returns the rollback
+// that removes the worktree registration, the directory if it did not exist
+// before and the branch if it was created. A failed add is rolled back
+// right away, since an interrupted git may leave parts of it behind.
+func (m *Manager) addWorktree(ctx context.Context, branch, path string, newBranch bool, args ...string) (rollback, error) {
+	_, err := os.Stat(path)
+	createdDir := os.IsNotExist(err)
+
+	var undo rollback
+	if newBranch {
+		undo.add(func(ctx context.Context) error {
+			if !m.BranchExists(ctx, branch) {
+				return nil
+			}
+			return m.runGitCommand(ctx, "branch", "-D", branch)
+		})
+	}
+	undo.add(func(ctx context.Context) error {
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			if err := m.runGitCommand(ctx, "worktree", "remove", "--force", "--force", path); err != nil {
+				return err
+			}
+		}
+		if !createdDir {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		return m.runGitCommand(ctx, "worktree", "prune")
+	})
+
+	if err := m.runGitCommand(ctx, args...); err != nil {
+		undo.run(ctx)
+		return nil, err
+	}
+	return undo, nil
+}
+
+// revertMetadata records in undo that the metadata of branch goes back to
+// what it is now, so that rolling back only drops what the change itself
+// recorded, such as the creation of a worktree for an existing branch.
+func (m *Manager) revertMetadata(ctx context.Context, undo *rollback, branch string) {
+	var prev *Metadata
+	if store, err := m.Metadata(ctx); err == nil {
+		prev, _ = store.Get(branch)
+	}
+	undo.add(func(ctx context.Context) error {
+		store, err := m.Metadata(ctx)
+		if err != nil {
+			return err
+		}
+		if prev == nil {
+			return store.Delete(branch)
+		}
+		return store.Update(branch, func(md *Metadata) { *md = *prev })
+	})
+}
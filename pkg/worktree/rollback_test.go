@@ -0,0 +1,108 @@
+package worktree_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestManagerCreateRollback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	for name, tt := range map[string]struct {
+		hook     string
+		timeout  time.Duration
+		existing bool
+	}{
+		"hook fails": {
+			hook:    "exit 3",
+			timeout: 30 * time.Second,
+		},
+		"interrupted": {
+			hook:    "sleep 10",
+			timeout: 500 * time.Millisecond,
+		},
+		"existing branch": {
+			hook:     "exit 3",
+			timeout:  30 * time.Second,
+			existing: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := t.TempDir()
+			runGit(t, repo, "init", "-q", "-b", "main")
+			writeTestFile(t, filepath.Join(repo, ".env"), "PORT=3000\n")
+			runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+			cfg := config.Default()
+			cfg.Hooks.PostCreate = []config.Hook{{Run: tt.hook}}
+			m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			// An existing branch keeps the notes it had before
+			opts := worktree.CreateOptions{From: "main", Fetch: config.FetchNever}
+			var want *worktree.Metadata
+			if tt.existing {
+				runGit(t, repo, "branch", "feature")
+				want = &worktree.Metadata{Description: "Rework login flow", Index: 3}
+				if err := m.UpdateMetadata(context.Background(), "feature", func(md *worktree.Metadata) {
+					*md = *want
+				}); err != nil {
+					t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+				}
+				opts.From = ""
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			if _, err := m.Create(ctx, "feature", opts); err == nil {
+				t.Fatal("Create() error = nil, want hook failure")
+			}
+
+			path := filepath.Join(m.WorktreeDir(), "feature")
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("worktree directory still exists after rollback: %v", err)
+			}
+			if got := m.BranchExists(context.Background(), "feature"); got != tt.existing {
+				t.Errorf("BranchExists() after rollback = %v, want %v", got, tt.existing)
+			}
+			if list := runGit(t, repo, "worktree", "list", "--porcelain"); strings.Contains(list, "feature") {
+				t.Errorf("worktree still registered after rollback:\n%s", list)
+			}
+
+			store, err := m.Metadata(context.Background())
+			if err != nil {
+				t.Fatalf("Metadata() unexpected error: %v", err)
+			}
+			md, err := store.Get("feature")
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(want, md); diff != "" {
+				t.Errorf("metadata after rollback mismatch (-want +got):\n%s", diff)
+			}
+
+			// A retry starts from scratch
+			cfg.Hooks.PostCreate = nil
+			if _, err := m.Create(context.Background(), "feature", opts); err != nil {
+				t.Errorf("Create() after rollback unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// TemplateSuffix marks files that are rendered into new worktrees. A file
+// such as .env.giwo.tmpl is rendered to .env.
+const TemplateSuffix = ".giwo.tmpl"
+
+// TemplateData is what template files are rendered with.
+type TemplateData struct {
+	// Branch is the branch of the worktree, or the name it was created
+	// with if it is detached.
+	Branch string
+	// Slug is Branch with slashes replaced by dashes.
+	Slug string
+	// Index is a small number, starting at 1, that no other worktree has.
+	Index int
+	// Port is the first port of the block reserved for the worktree.
+	Port int
+	// Ports lists the ports of the block reserved for the worktree.
+	Ports []int
+	// Path is the path of the worktree.
+	Path string
+	// Base is the branch the worktree was created from, if known.
+	Base string
+	// Repo is the name of the repository root directory.
+	Repo string
+	// RepoRoot is the path of the main worktree.
+	RepoRoot string
+}
+
+// templateData returns the data the templates of the worktree of branch at
+// path are rendered with. It assigns the worktree its index and ports, and
+// returns the data without them if that fails.
+func (m *Manager) templateData(ctx context.Context, branch, path, base string) (TemplateData, error) {
+	data := m.newTemplateData(branch, path, base)
+
+	store, err := m.Metadata(ctx)
+	if err != nil {
+		return data, err
+	}
+	if data.Index, err = store.AssignIndex(branch); err != nil {
+		return data, fmt.Errorf("failed to assign worktree index: %w", err)
+	}
+	ports, err := store.AssignPorts(branch, m.cfg.Ports.Base, m.cfg.Ports.Size)
+	if err != nil {
+		return data, fmt.Errorf("failed to reserve ports: %w", err)
+	}
+	data.Port, data.Ports = ports.Start, ports.Ports()
+	return data, nil
+}
+
+// newTemplateData returns the data of the worktree of branch at path that
+// needs no index or ports.
+func (m *Manager) newTemplateData(branch, path, base string) TemplateData {
+	return TemplateData{
+		Branch:   branch,
+		Slug:     strings.ReplaceAll(branch, "/", "-"),
+		Path:     path,
+		Base:     base,
+		Repo:     filepath.Base(m.repoRoot),
+		RepoRoot: m.repoRoot,
+	}
+}
+
+// renderTemplates renders the template files of the repository into the
+// worktree at path, each next to its template without the suffix. Templates
+// are read from the worktree, or from the main worktree if they are not
+// checked out, so that untracked templates apply to every new worktree.
+// Rendered files replace copied ones.
+func (m *Manager) renderTemplates(ctx context.Context, path string, data TemplateData) error {
+	output, err := m.runner.Run(ctx, GitCommand{
+		Dir:  m.repoRoot,
+		Args: []string{"ls-files", "-z", "--cached", "--others", "--", ":(glob)**/*" + TemplateSuffix},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	for _, name := range strings.Split(string(output), "\x00") {
+		target := strings.TrimSuffix(name, TemplateSuffix)
+		if name == "" || strings.HasSuffix(target, "/") || target == "" {
+			continue
+		}
+
+		src := filepath.Join(path, filepath.FromSlash(name))
+		if _, err := os.Stat(src); err != nil {
+			src = filepath.Join(m.repoRoot, filepath.FromSlash(name))
+		}
+		if err := renderTemplate(src, filepath.Join(path, filepath.FromSlash(target)), data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// renderTemplate renders the template file src to dst with the mode of src.
+func renderTemplate(src, dst string, data TemplateData) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	text, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(filepath.Base(src)).Parse(string(text))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	// Do not write through a symlink made by symlink_files
+	os.Remove(dst)
+	if err := os.WriteFile(dst, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
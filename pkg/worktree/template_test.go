@@ -0,0 +1,130 @@
+package worktree_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/knwoop/giwo/pkg/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestManagerCreateRendersTemplates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), ".env\nconfig/\n.worktree/\n")
+	writeTestFile(t, filepath.Join(repo, ".env.giwo.tmpl"), "DATABASE=app_{{.Slug}}\nINDEX={{.Index}}\nPORT={{.Port}}\nAPI_PORT={{index .Ports 1}}\n")
+	runGit(t, repo, "add", ".gitignore", ".env.giwo.tmpl")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	// An untracked template of the main worktree applies as well, and the
+	// rendered .env replaces the link to the main worktree's
+	if err := os.MkdirAll(filepath.Join(repo, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(repo, "config", "dev.yml.giwo.tmpl"), "worktree: {{.Repo}}/{{.Branch}}\n")
+	writeTestFile(t, filepath.Join(repo, ".env"), "DATABASE=app\n")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.SymlinkFiles = []string{".env"}
+	cfg.Ports = config.Ports{Base: 3000, Size: 5}
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	opts := worktree.CreateOptions{From: "main", Fetch: config.FetchNever}
+	for _, branch := range []string{"feature/login", "bugfix"} {
+		if _, err := m.Create(ctx, branch, opts); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", branch, err)
+		}
+	}
+
+	for path, want := range map[string]string{
+		filepath.Join(m.WorktreeDir(), "feature", "login", ".env"):    "DATABASE=app_feature-login\nINDEX=1\nPORT=3000\nAPI_PORT=3001\n",
+		filepath.Join(m.WorktreeDir(), "bugfix", ".env"):              "DATABASE=app_bugfix\nINDEX=2\nPORT=3005\nAPI_PORT=3006\n",
+		filepath.Join(m.WorktreeDir(), "bugfix", "config", "dev.yml"): "worktree: " + filepath.Base(repo) + "/bugfix\n",
+		filepath.Join(repo, ".env"):                                   "DATABASE=app\n",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("ReadFile() unexpected error: %v", err)
+			continue
+		}
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", path, diff)
+		}
+	}
+
+	// A broken template fails the creation, which is rolled back
+	writeTestFile(t, filepath.Join(repo, "config", "dev.yml.giwo.tmpl"), "worktree: {{.Nope}}\n")
+	if _, err := m.Create(ctx, "broken", opts); err == nil {
+		t.Errorf("Create() with a broken template error = nil, want an error")
+	}
+	if m.BranchExists(ctx, "broken") {
+		t.Errorf("branch still exists after a failed Create()")
+	}
+}
+
+func TestManagerCreateWritesEnvrc(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	cfg := config.Default()
+	cfg.CopyFiles = nil
+	cfg.Env.Envrc = true
+	m, err := worktree.New(worktree.WithRepoRoot(repo), worktree.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	opts := worktree.CreateOptions{From: "main", Fetch: config.FetchNever}
+	for _, branch := range []string{"feature", "bugfix"} {
+		if _, err := m.Create(ctx, branch, opts); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", branch, err)
+		}
+	}
+
+	path := filepath.Join(m.WorktreeDir(), "feature")
+	if _, err := os.Stat(filepath.Join(path, worktree.EnvrcFile)); err != nil {
+		t.Fatalf("Stat(.envrc) unexpected error: %v", err)
+	}
+
+	// The generated file is excluded once and does not block the removal
+	exclude, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatalf("ReadFile(info/exclude) unexpected error: %v", err)
+	}
+	if n := strings.Count(string(exclude), "/.envrc\n"); n != 1 {
+		t.Errorf("info/exclude lists /.envrc %d times, want once:\n%s", n, exclude)
+	}
+	if err := m.Remove(ctx, "feature", worktree.RemoveOptions{KeepBranch: true, Yes: true}); err != nil {
+		t.Errorf("Remove() unexpected error: %v", err)
+	}
+}
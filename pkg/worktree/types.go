@@ -2,7 +2,10 @@
 package worktree
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/knwoop/giwo/pkg/config"
 )
 
 // OutputFormat represents the output format for worktree listings.
@@ -15,15 +18,9 @@ const (
 	OutputFormatSimple OutputFormat = "simple"
 )
 
-// Config file names that should be copied to new worktrees.
-var ConfigFiles = []string{
-	".editorconfig",
-	".env",
-	".env.local",
-	".gitignore",
-	".prettierrc",
-	".rgignore",
-}
+// ConfigFiles lists the config file names copied to new worktrees when the
+// configuration does not override them.
+var ConfigFiles = config.DefaultCopyFiles
 
 // Worktree represents a Git worktree with its current status.
 // Fields are ordered by importance: identifying fields first, then status fields.
@@ -32,10 +29,28 @@ type Worktree struct {
 	Path   string `json:"path"`
 	Branch string `json:"branch"`
 
+	// Head is the commit checked out in the worktree. Detached worktrees
+	// have no branch.
+	Head     string `json:"head"`
+	Detached bool   `json:"detached,omitempty"`
+
+	// Bare means the entry is the bare repository itself, which has no
+	// working tree or HEAD.
+	Bare bool `json:"bare,omitempty"`
+
 	// Status flags
 	IsMain  bool `json:"is_main"`
 	IsClean bool `json:"is_clean"`
 
+	// Lock state set with 'git worktree lock'
+	Locked     bool   `json:"locked,omitempty"`
+	LockReason string `json:"lock_reason,omitempty"`
+
+	// Prunable means the worktree can be pruned, usually because its
+	// directory is gone. Status fields are not read for it.
+	Prunable       bool   `json:"prunable,omitempty"`
+	PrunableReason string `json:"prunable_reason,omitempty"`
+
 	// Sync status with remote
 	Ahead  int `json:"ahead"`
 	Behind int `json:"behind"`
@@ -49,6 +64,69 @@ type Worktree struct {
 	LastCommit string    `json:"last_commit"`
 	CommitAge  string    `json:"commit_age"`
 	CommitTime time.Time `json:"commit_time"`
+
+	// Metadata is what giwo recorded about the worktree, if anything.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Error describes why the status of the worktree could not be read.
+	// Status fields are incomplete when it is set.
+	Error string `json:"error,omitempty"`
+}
+
+// Name returns the branch of the worktree, the abbreviated commit for a
+// detached worktree, or "(bare)" for a bare repository.
+func (wt *Worktree) Name() string {
+	if wt.Bare {
+		return "(bare)"
+	}
+	if wt.Branch == "" {
+		return shortSHA(wt.Head)
+	}
+	return wt.Branch
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 7)]
+}
+
+// MergeReason explains why a branch is considered merged.
+type MergeReason string
+
+// Merge reason constants.
+const (
+	// MergeReasonMerged means the branch tip is reachable from the base branch.
+	MergeReasonMerged MergeReason = "merged"
+	// MergeReasonRebased means every commit has an equivalent patch in the base branch.
+	MergeReasonRebased MergeReason = "rebase-merged"
+	// MergeReasonSquashed means the combined changes have an equivalent patch in the base branch.
+	MergeReasonSquashed MergeReason = "squash-merged"
+	// MergeReasonPullRequest means the forge reports a merged pull request for the branch.
+	MergeReasonPullRequest MergeReason = "pull-request-merged"
+)
+
+// MergedBranch is a branch whose work has landed in the base branch.
+type MergedBranch struct {
+	Branch      string      `json:"branch"`
+	Base        string      `json:"base"`
+	Reason      MergeReason `json:"reason"`
+	PullRequest int         `json:"pull_request,omitempty"`
+}
+
+// Description returns a human-readable explanation of why the branch is merged.
+func (b MergedBranch) Description() string {
+	switch b.Reason {
+	case MergeReasonMerged:
+		return fmt.Sprintf("merged into %s", b.Base)
+	case MergeReasonRebased:
+		return fmt.Sprintf("rebase-merged into %s", b.Base)
+	case MergeReasonSquashed:
+		return fmt.Sprintf("squash-merged into %s", b.Base)
+	case MergeReasonPullRequest:
+		return fmt.Sprintf("pull request #%d merged", b.PullRequest)
+	default:
+		return string(b.Reason)
+	}
 }
 
 // Stats represents statistics about all worktrees.
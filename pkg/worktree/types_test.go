@@ -47,3 +47,35 @@ func TestConfigFiles(t *testing.T) {
 		t.Error("ConfigFiles should not be empty")
 	}
 }
+
+func TestMergedBranchDescription(t *testing.T) {
+	for name, tt := range map[string]struct {
+		branch   MergedBranch
+		expected string
+	}{
+		"regular merge": {
+			branch:   MergedBranch{Branch: "feature", Base: "origin/main", Reason: MergeReasonMerged},
+			expected: "merged into origin/main",
+		},
+		"squash merge": {
+			branch:   MergedBranch{Branch: "feature", Base: "origin/main", Reason: MergeReasonSquashed},
+			expected: "squash-merged into origin/main",
+		},
+		"rebase merge": {
+			branch:   MergedBranch{Branch: "feature", Base: "origin/main", Reason: MergeReasonRebased},
+			expected: "rebase-merged into origin/main",
+		},
+		"pull request": {
+			branch:   MergedBranch{Branch: "feature", Base: "origin/main", Reason: MergeReasonPullRequest, PullRequest: 42},
+			expected: "pull request #42 merged",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, tt.branch.Description()); diff != "" {
+				t.Errorf("Description() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
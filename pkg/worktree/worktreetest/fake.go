@@ -0,0 +1,121 @@
+// Package worktreetest provides utilities for testing code that uses the
+// worktree package without a real Git repository.
+package worktreetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// FakeRunner is a worktree.GitRunner that answers git commands from scripted
+// responses and records every invocation.
+// Commands without a matching response fail with an error.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses []*Response
+	calls     []worktree.GitCommand
+}
+
+// Response is a scripted answer to a git command.
+type Response struct {
+	args   []string
+	dir    string
+	output string
+	err    error
+}
+
+// NewFakeRunner returns a FakeRunner without any scripted responses.
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{}
+}
+
+// On scripts a response for the git command with exactly args.
+// The response succeeds with empty output unless configured otherwise.
+// When several responses match, the one scripted last wins.
+func (f *FakeRunner) On(args ...string) *Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := &Response{args: args}
+	f.responses = append(f.responses, r)
+	return r
+}
+
+// InDir restricts the response to commands run in dir.
+func (r *Response) InDir(dir string) *Response {
+	r.dir = dir
+	return r
+}
+
+// Return makes the command succeed with output.
+func (r *Response) Return(output string) *Response {
+	r.output = output
+	r.err = nil
+	return r
+}
+
+// Fail makes the command fail as if git exited with stderr as error output.
+func (r *Response) Fail(stderr string) *Response {
+	r.err = fmt.Errorf("exit status 1: %s", stderr)
+	return r
+}
+
+// Run implements worktree.GitRunner.
+func (f *FakeRunner) Run(ctx context.Context, cmd worktree.GitCommand) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, cmd)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		r := f.responses[i]
+		if !slices.Equal(r.args, cmd.Args) || (r.dir != "" && r.dir != cmd.Dir) {
+			continue
+		}
+		if r.err != nil {
+			return nil, newGitError(cmd.Args, r.err)
+		}
+		return []byte(r.output), nil
+	}
+
+	return nil, newGitError(cmd.Args, fmt.Errorf("worktreetest: unexpected command %q in %s", strings.Join(cmd.Args, " "), cmd.Dir))
+}
+
+// Calls returns the commands run so far, in order.
+func (f *FakeRunner) Calls() []worktree.GitCommand {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// CommandLines returns the arguments of the commands run so far joined by
+// spaces, which is convenient for comparing against an expected sequence.
+func (f *FakeRunner) CommandLines() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lines := make([]string, 0, len(f.calls))
+	for _, c := range f.calls {
+		lines = append(lines, strings.Join(c.Args, " "))
+	}
+	return lines
+}
+
+// newGitError wraps err like worktree.ExecRunner does.
+func newGitError(args []string, err error) error {
+	if len(args) == 0 {
+		return errors.NewGitError("", nil, err)
+	}
+	return errors.NewGitError(args[0], args[1:], err)
+}